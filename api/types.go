@@ -3,6 +3,7 @@ package api
 import (
 	conditions "github.com/openshift/custom-resource-status/conditions/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Phase is the current phase of the deployment
@@ -42,6 +43,72 @@ type Status struct {
 	TargetVersion string `json:"targetVersion,omitempty" optional:"true"`
 	// The observed version of the resource
 	ObservedVersion string `json:"observedVersion,omitempty" optional:"true"`
+	// The time the resource entered the current phase
+	PhaseTransitionTime *metav1.Time `json:"phaseTransitionTime,omitempty" optional:"true"`
+	// The generation of the resource last reconciled successfully
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" optional:"true"`
+	// The generation of the resource each condition was last changed for, by condition type
+	ConditionGenerations map[string]int64 `json:"conditionGenerations,omitempty" optional:"true"`
+	// The objects managed by the resource
+	RelatedObjects []corev1.ObjectReference `json:"relatedObjects,omitempty" optional:"true"`
+	// The sync status of the objects managed by the resource, if enabled in the operator
+	Resources []ResourceStatus `json:"resources,omitempty" optional:"true"`
+}
+
+// MetaStatus represents status of a operator configuration resource with metav1.Condition conditions, the alternative
+// to Status; must be inlined in the operator configuration resource status
+type MetaStatus struct {
+	Phase Phase `json:"phase,omitempty"`
+	// A list of current conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" optional:"true" patchStrategy:"merge" patchMergeKey:"type"`
+	// The version of the resource as defined by the operator
+	OperatorVersion string `json:"operatorVersion,omitempty" optional:"true"`
+	// The desired version of the resource
+	TargetVersion string `json:"targetVersion,omitempty" optional:"true"`
+	// The observed version of the resource
+	ObservedVersion string `json:"observedVersion,omitempty" optional:"true"`
+	// The time the resource entered the current phase
+	PhaseTransitionTime *metav1.Time `json:"phaseTransitionTime,omitempty" optional:"true"`
+	// The generation of the resource last reconciled successfully
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" optional:"true"`
+	// The objects managed by the resource
+	RelatedObjects []corev1.ObjectReference `json:"relatedObjects,omitempty" optional:"true"`
+	// The sync status of the objects managed by the resource, if enabled in the operator
+	Resources []ResourceStatus `json:"resources,omitempty" optional:"true"`
+}
+
+// ResourceSyncResult is the result of the last reconciliation of a managed object
+type ResourceSyncResult string
+
+const (
+	// ResourceSyncPending signals that the object hasn't been reconciled yet
+	ResourceSyncPending ResourceSyncResult = "Pending"
+
+	// ResourceSyncSynced signals that the object has been brought to its desired state
+	ResourceSyncSynced ResourceSyncResult = "Synced"
+
+	// ResourceSyncFailed signals that the object couldn't be created or updated
+	ResourceSyncFailed ResourceSyncResult = "Failed"
+)
+
+// ResourceStatus represents the sync status of an object managed by the operator configuration resource
+type ResourceStatus struct {
+	// API version of the object
+	APIVersion string `json:"apiVersion"`
+	// Kind of the object
+	Kind string `json:"kind"`
+	// Namespace of the object, empty for cluster scoped ones
+	Namespace string `json:"namespace,omitempty" optional:"true"`
+	// Name of the object
+	Name string `json:"name"`
+	// The result of the last reconciliation of the object
+	SyncResult ResourceSyncResult `json:"syncResult"`
+	// The error of the last reconciliation of the object, if it failed
+	Error string `json:"error,omitempty" optional:"true"`
+	// Whether the object is ready
+	Ready bool `json:"ready"`
 }
 
 // NodePlacement describes node scheduling configuration.
@@ -82,6 +149,63 @@ func (in *Status) DeepCopyInto(out *Status) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PhaseTransitionTime != nil {
+		in, out := &in.PhaseTransitionTime, &out.PhaseTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ConditionGenerations != nil {
+		in, out := &in.ConditionGenerations, &out.ConditionGenerations
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RelatedObjects != nil {
+		in, out := &in.RelatedObjects, &out.RelatedObjects
+		*out = make([]corev1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto is copying the receiver, writing into out. in must be non-nil.
+func (in *MetaStatus) DeepCopyInto(out *MetaStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PhaseTransitionTime != nil {
+		in, out := &in.PhaseTransitionTime, &out.PhaseTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RelatedObjects != nil {
+		in, out := &in.RelatedObjects, &out.RelatedObjects
+		*out = make([]corev1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is copying the receiver, creating a new MetaStatus.
+func (in *MetaStatus) DeepCopy() *MetaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetaStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -62,6 +62,7 @@ type ReconcileState string
 
 // ReconcileCallbackArgs contains the data of a ReconcileCallback
 type ReconcileCallbackArgs struct {
+	Context   context.Context
 	Logger    logr.Logger
 	Client    client.Client
 	Scheme    *runtime.Scheme
@@ -113,6 +114,11 @@ func (cd *CallbackDispatcher) AddCallback(obj client.Object, cb ReconcileCallbac
 
 // InvokeCallbacks executes callbacks for desired/current object type
 func (cd *CallbackDispatcher) InvokeCallbacks(l logr.Logger, cr interface{}, s ReconcileState, desiredObj, currentObj client.Object, recorder record.EventRecorder) error {
+	return cd.InvokeCallbacksContext(context.TODO(), l, cr, s, desiredObj, currentObj, recorder)
+}
+
+// InvokeCallbacksContext executes callbacks for desired/current object type passing them given context
+func (cd *CallbackDispatcher) InvokeCallbacksContext(ctx context.Context, l logr.Logger, cr interface{}, s ReconcileState, desiredObj, currentObj client.Object, recorder record.EventRecorder) error {
 	var t reflect.Type
 
 	if desiredObj != nil {
@@ -133,7 +139,7 @@ func (cd *CallbackDispatcher) InvokeCallbacks(l logr.Logger, cr interface{}, s R
 			}
 
 			currentObj = sdk.NewDefaultInstance(desiredObj)
-			if err := cd.client.Get(context.TODO(), key, currentObj); err != nil {
+			if err := cd.client.Get(ctx, key, currentObj); err != nil {
 				if !errors.IsNotFound(err) {
 					return err
 				}
@@ -141,6 +147,7 @@ func (cd *CallbackDispatcher) InvokeCallbacks(l logr.Logger, cr interface{}, s R
 			}
 		}
 		args := ReconcileCallbackArgs{
+			Context:       ctx,
 			Logger:        l,
 			Client:        cd.uncachedClient,
 			Scheme:        cd.scheme,
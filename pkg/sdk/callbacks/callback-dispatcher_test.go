@@ -1,6 +1,7 @@
 package callbacks_test
 
 import (
+	"context"
 	"fmt"
 
 	. "github.com/onsi/ginkgo"
@@ -184,6 +185,35 @@ var _ = Describe("Callback dispatcher ", func() {
 		Expect(args.Resource).To(Equal(cr))
 	})
 
+	It("should pass the context to callbacks", func() {
+		desiredObj := v1.ConfigMap{}
+		currentObj := v1.ConfigMap{}
+		cr := testcr.Config{}
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		callbackArguments := new([]*callbacks.ReconcileCallbackArgs)
+		callback := func(args *callbacks.ReconcileCallbackArgs) error {
+			tmp := append(*callbackArguments, args)
+			callbackArguments = &tmp
+			return nil
+		}
+
+		By("registering callback")
+		cd.AddCallback(&desiredObj, callback)
+
+		By("invoking callback")
+
+		err := cd.InvokeCallbacksContext(ctx, log, cr, callbacks.ReconcileStatePreUpdate, &desiredObj, &currentObj, recorder)
+
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(*callbackArguments).To(HaveLen(1))
+		args := (*callbackArguments)[0]
+
+		Expect(args.Context).To(Equal(ctx))
+	})
+
 	It("should propagate callback error", func() {
 		desiredObj := v1.Pod{}
 		currentObj := v1.Pod{}
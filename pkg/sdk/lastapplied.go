@@ -0,0 +1,143 @@
+package sdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const compressedLastAppliedPrefix = "gzip+base64:"
+
+// LastAppliedHashPrefix starts the annotation values referencing a configuration kept outside of the object by its
+// hash, which the annotation stores don't know
+const LastAppliedHashPrefix = "sha256:"
+
+// DefaultMaxLastAppliedSize is the size of the compressed last applied configuration the annotation stores refuse to
+// exceed by default, half of the size all annotations of an object are limited to
+const DefaultMaxLastAppliedSize = 128 * 1024
+
+// LastAppliedStore records the last applied configuration of managed objects, which MergeObjectWithStore computes the
+// three-way merge with
+type LastAppliedStore interface {
+	// Save records the configuration of obj, leaving a reference to it (if not the configuration itself) in its
+	// annotations
+	Save(ctx context.Context, obj client.Object) error
+	// Load returns the last applied configuration of obj, nil if unknown
+	Load(ctx context.Context, obj client.Object) ([]byte, error)
+}
+
+// annotationStore keeps the last applied configuration in an annotation
+type annotationStore struct {
+	annotation string
+	compress   bool
+	maxSize    int
+}
+
+// NewAnnotationStore returns a LastAppliedStore keeping the JSON of the last applied configuration in given annotation,
+// as SetLastAppliedConfiguration does
+func NewAnnotationStore(annotation string) LastAppliedStore {
+	return &annotationStore{annotation: annotation}
+}
+
+// NewCompressedAnnotationStore returns a LastAppliedStore keeping the gzipped and base64 encoded last applied
+// configuration in given annotation. Saving fails if the encoded configuration exceeds maxSize bytes.
+func NewCompressedAnnotationStore(annotation string, maxSize int) LastAppliedStore {
+	return &annotationStore{annotation: annotation, compress: true, maxSize: maxSize}
+}
+
+func (s *annotationStore) Save(_ context.Context, obj client.Object) error {
+	if !s.compress {
+		return SetLastAppliedConfiguration(obj, s.annotation)
+	}
+
+	config, err := LastAppliedConfiguration(obj)
+	if err != nil {
+		return err
+	}
+	value, err := CompressLastAppliedConfiguration(config)
+	if err != nil {
+		return err
+	}
+	if len(value) > s.maxSize {
+		return fmt.Errorf("compressed last applied configuration of %T %s has %d bytes, more than %d allowed", obj, obj.GetName(), len(value), s.maxSize)
+	}
+	SetAnnotation(s.annotation, value, obj)
+	return nil
+}
+
+func (s *annotationStore) Load(_ context.Context, obj client.Object) ([]byte, error) {
+	value, ok := obj.GetAnnotations()[s.annotation]
+	if !ok || strings.HasPrefix(value, LastAppliedHashPrefix) {
+		// a hash is recorded by another storage, the configuration is unknown
+		return nil, nil
+	}
+	return DecodeLastAppliedConfiguration(value)
+}
+
+// LastAppliedConfiguration returns the configuration of obj to record as the last applied one
+func LastAppliedConfiguration(obj client.Object) ([]byte, error) {
+	return json.Marshal(obj)
+}
+
+// CompressLastAppliedConfiguration encodes the configuration in the compressed form DecodeLastAppliedConfiguration
+// accepts
+func CompressLastAppliedConfiguration(config []byte) (string, error) {
+	compressed, err := Compress(config)
+	if err != nil {
+		return "", err
+	}
+	return compressedLastAppliedPrefix + base64.StdEncoding.EncodeToString(compressed), nil
+}
+
+// DecodeLastAppliedConfiguration returns the configuration of an annotation value, either the plain JSON or the
+// compressed form
+func DecodeLastAppliedConfiguration(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, compressedLastAppliedPrefix) {
+		return []byte(value), nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, compressedLastAppliedPrefix))
+	if err != nil {
+		return nil, err
+	}
+	return Decompress(compressed)
+}
+
+// Compress gzips data
+func Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress reads data gzipped by Compress
+func Decompress(compressed []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// SetAnnotation sets the annotation of obj
+func SetAnnotation(key, value string, obj metav1.Object) {
+	if obj.GetAnnotations() == nil {
+		obj.SetAnnotations(make(map[string]string))
+	}
+	obj.GetAnnotations()[key] = value
+}
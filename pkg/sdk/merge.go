@@ -0,0 +1,133 @@
+package sdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// threeWayMerge applies to current the changes made by modified, with the semantics of the JSON merge patch created by
+// jsonmergepatch.CreateThreeWayJSONMergePatch: fields set in modified are set in current, fields set to null in
+// modified or set in original only are removed from current, lists are replaced as a whole. The maps of modified are
+// reused by current.
+func threeWayMerge(original, modified, current map[string]interface{}) {
+	for key, modifiedValue := range modified {
+		if modifiedValue == nil {
+			if originalValue, ok := original[key]; !ok || originalValue != nil {
+				delete(current, key)
+			}
+			continue
+		}
+
+		modifiedMap, ok := modifiedValue.(map[string]interface{})
+		if !ok {
+			current[key] = modifiedValue
+			continue
+		}
+		if currentMap, ok := current[key].(map[string]interface{}); ok {
+			originalMap, _ := original[key].(map[string]interface{})
+			threeWayMerge(originalMap, modifiedMap, currentMap)
+			continue
+		}
+		pruneNulls(modifiedMap)
+		current[key] = modifiedMap
+	}
+
+	for key := range original {
+		if _, ok := modified[key]; !ok {
+			delete(current, key)
+		}
+	}
+}
+
+// pruneNulls removes the null fields of content, a merge patch never sets them
+func pruneNulls(content map[string]interface{}) {
+	for key, value := range content {
+		switch typedValue := value.(type) {
+		case nil:
+			delete(content, key)
+		case map[string]interface{}:
+			pruneNulls(typedValue)
+		}
+	}
+}
+
+// objectIdentity returns the fields of content identifying the object, a merge must not change them
+func objectIdentity(content map[string]interface{}) []interface{} {
+	return []interface{}{content["apiVersion"], content["kind"], nestedValue(content, "metadata", "name")}
+}
+
+func nestedValue(content map[string]interface{}, path ...string) interface{} {
+	var value interface{} = content
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// decodeJSONMap decodes the JSON of a last applied configuration, an empty configuration decodes into an empty map
+func decodeJSONMap(data []byte) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if len(data) == 0 {
+		return result, nil
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// toUnstructuredMap returns the content of obj as a map, converting typed objects field by field instead of
+// serializing them
+func toUnstructuredMap(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return runtime.DeepCopyJSON(u.UnstructuredContent()), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// fromUnstructuredMap returns a new object of the type of template holding content
+func fromUnstructuredMap(content map[string]interface{}, template client.Object) (client.Object, error) {
+	result := NewDefaultInstance(template)
+	if u, ok := result.(runtime.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+		return result, nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// clearStatusField zeroes the top level field of the typed object obj serialized as "status" or "Status"
+func clearStatusField(obj runtime.Object) {
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if name := jsonFieldName(field); name == statusKey || name == capitalStatusKey {
+			value.Field(i).Set(reflect.Zero(field.Type))
+		}
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,214 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/mergepatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const benchmarkObjects = 200
+
+// jsonMergeObject is the JSON merge patch based implementation MergeObjectWithStore replaced, kept as the reference
+// of its semantics and performance
+func jsonMergeObject(desiredObj, currentObj client.Object, lastAppliedConfigAnnotation string) (client.Object, error) {
+	desiredObj = desiredObj.DeepCopyObject().(client.Object)
+	desiredObj.SetCreationTimestamp(currentObj.GetCreationTimestamp())
+
+	original, err := NewAnnotationStore(lastAppliedConfigAnnotation).Load(context.Background(), currentObj)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := json.Marshal(desiredObj)
+	if err != nil {
+		return nil, err
+	}
+	current, err := json.Marshal(currentObj)
+	if err != nil {
+		return nil, err
+	}
+
+	preconditions := []mergepatch.PreconditionFunc{
+		mergepatch.RequireKeyUnchanged("apiVersion"),
+		mergepatch.RequireKeyUnchanged("kind"),
+		mergepatch.RequireMetadataKeyUnchanged("name"),
+	}
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current, preconditions...)
+	if err != nil {
+		return nil, err
+	}
+	newCurrent, err := jsonpatch.MergePatch(current, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewDefaultInstance(currentObj)
+	if err = json.Unmarshal(newCurrent, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// jsonStripStatusFromObject is the JSON based implementation StripStatusFromObject replaced
+func jsonStripStatusFromObject(obj client.Object) (client.Object, error) {
+	modified, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	modified, err = StripStatusByte(modified)
+	if err != nil {
+		return nil, err
+	}
+	result := NewDefaultInstance(obj)
+	if err = json.Unmarshal(modified, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// benchmarkDeployment returns the desired and the live state of a large Deployment: the live one is defaulted, carries
+// a status and the last applied configuration of a previous version of the desired one
+func benchmarkDeployment(i int) (client.Object, client.Object) {
+	previous := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("deployment-%d", i),
+			Namespace: "bench",
+			Labels:    map[string]string{"app": "bench", "index": fmt.Sprint(i)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &[]int32{3}[0],
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "bench"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "bench"}},
+			},
+		},
+	}
+	for c := 0; c < 8; c++ {
+		container := corev1.Container{
+			Name:  fmt.Sprintf("container-%d", c),
+			Image: fmt.Sprintf("registry/image-%d:v1", c),
+			Args:  []string{"--verbose", "--port", fmt.Sprint(8000 + c)},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		}
+		for e := 0; e < 10; e++ {
+			container.Env = append(container.Env, corev1.EnvVar{Name: fmt.Sprintf("VAR_%d", e), Value: fmt.Sprint(e)})
+		}
+		previous.Spec.Template.Spec.Containers = append(previous.Spec.Template.Spec.Containers, container)
+		previous.Spec.Template.Spec.Volumes = append(previous.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         container.Name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+	previous.Spec.MinReadySeconds = 10
+	if err := SetLastAppliedConfiguration(previous, lastsAppliedConfigurationAnnotation); err != nil {
+		panic(err)
+	}
+
+	current := previous.DeepCopy()
+	current.ResourceVersion = "42"
+	// in local time, as decoded by the clients
+	current.CreationTimestamp = metav1.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	current.Spec.RevisionHistoryLimit = &[]int32{10}[0]
+	current.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+	current.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	current.Status = appsv1.DeploymentStatus{Replicas: 3, ReadyReplicas: 3, ObservedGeneration: 1}
+
+	desired := previous.DeepCopy()
+	desired.Annotations = nil
+	desired.Spec.MinReadySeconds = 0
+	desired.Spec.Template.Spec.Containers[0].Image = "registry/image-0:v2"
+	return desired, current
+}
+
+// benchmarkConfigMap returns the desired and the live state of a ConfigMap with many entries
+func benchmarkConfigMap(i int) (client.Object, client.Object) {
+	previous := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("config-%d", i), Namespace: "bench"},
+		Data:       map[string]string{},
+	}
+	for d := 0; d < 50; d++ {
+		previous.Data[fmt.Sprintf("key-%d", d)] = fmt.Sprintf("value-%d", d)
+	}
+	if err := SetLastAppliedConfiguration(previous, lastsAppliedConfigurationAnnotation); err != nil {
+		panic(err)
+	}
+
+	current := previous.DeepCopy()
+	current.ResourceVersion = "42"
+	current.Data["user-key"] = "user-value"
+
+	desired := previous.DeepCopy()
+	desired.Annotations = nil
+	delete(desired.Data, "key-0")
+	desired.Data["key-1"] = "changed"
+	return desired, current
+}
+
+func benchmarkObjectSet() ([]client.Object, []client.Object) {
+	var desired, current []client.Object
+	for i := 0; i < benchmarkObjects; i++ {
+		for _, create := range []func(int) (client.Object, client.Object){benchmarkDeployment, benchmarkConfigMap} {
+			d, c := create(i)
+			desired = append(desired, d)
+			current = append(current, c)
+		}
+	}
+	return desired, current
+}
+
+func BenchmarkMergeObject(b *testing.B) {
+	desired, current := benchmarkObjectSet()
+	merges := map[string]func(desiredObj, currentObj client.Object, lastAppliedConfigAnnotation string) (client.Object, error){
+		"json":         jsonMergeObject,
+		"unstructured": MergeObject,
+	}
+	for _, name := range []string{"json", "unstructured"} {
+		merge := merges[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				for i := range desired {
+					if _, err := merge(desired[i], current[i], lastsAppliedConfigurationAnnotation); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStripStatusFromObject(b *testing.B) {
+	_, current := benchmarkObjectSet()
+	strips := map[string]func(client.Object) (client.Object, error){
+		"json":       jsonStripStatusFromObject,
+		"reflection": StripStatusFromObject,
+	}
+	for _, name := range []string{"json", "reflection"} {
+		strip := strips[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				for _, obj := range current {
+					if _, err := strip(obj); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
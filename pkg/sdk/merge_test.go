@@ -0,0 +1,91 @@
+package sdk
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Unstructured merge", func() {
+	DescribeTable("should merge the same way as the JSON merge patch", func(create func() (client.Object, client.Object)) {
+		desired, current := create()
+
+		expected, err := jsonMergeObject(desired, current, lastsAppliedConfigurationAnnotation)
+		Expect(err).ToNot(HaveOccurred())
+		merged, err := MergeObject(desired, current, lastsAppliedConfigurationAnnotation)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(merged).To(Equal(expected))
+	},
+		Entry("Deployment", func() (client.Object, client.Object) {
+			return benchmarkDeployment(0)
+		}),
+		Entry("ConfigMap", func() (client.Object, client.Object) {
+			return benchmarkConfigMap(0)
+		}),
+		Entry("Deployment without last applied configuration", func() (client.Object, client.Object) {
+			desired, current := benchmarkDeployment(0)
+			current.SetAnnotations(nil)
+			return desired, current
+		}),
+		Entry("Deployment with fields removed from template", func() (client.Object, client.Object) {
+			desired, current := benchmarkDeployment(0)
+			deployment := desired.(*appsv1.Deployment)
+			deployment.Spec.Selector = nil
+			deployment.Spec.Template.Spec.Volumes = nil
+			deployment.Spec.Template.Labels = nil
+			return desired, current
+		}),
+		Entry("Deployment with fields added", func() (client.Object, client.Object) {
+			desired, current := benchmarkDeployment(0)
+			deployment := desired.(*appsv1.Deployment)
+			deployment.Spec.Template.Spec.NodeSelector = map[string]string{"node": "bench"}
+			deployment.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &[]bool{true}[0]}
+			return desired, current
+		}),
+		Entry("unstructured object", func() (client.Object, client.Object) {
+			desired, current := benchmarkConfigMap(0)
+			gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+			return toUnstructured(desired, gvk), toUnstructured(current, gvk)
+		}),
+	)
+
+	It("should refuse to rename the object", func() {
+		desired, current := benchmarkConfigMap(0)
+		desired.SetName("renamed")
+
+		_, err := MergeObject(desired, current, lastsAppliedConfigurationAnnotation)
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("should strip status the same way as the JSON round trip", func(obj client.Object) {
+		expected, err := jsonStripStatusFromObject(obj)
+		Expect(err).ToNot(HaveOccurred())
+		stripped, err := StripStatusFromObject(obj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stripped).To(Equal(expected))
+	},
+		Entry("Deployment", func() client.Object {
+			_, current := benchmarkDeployment(0)
+			return current
+		}()),
+		Entry("unstructured object", toUnstructured(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "unstructured"},
+			Status:     appsv1.DeploymentStatus{Replicas: 1},
+		}, appsv1.SchemeGroupVersion.WithKind("Deployment"))),
+	)
+})
+
+func toUnstructured(obj client.Object, gvk schema.GroupVersionKind) client.Object {
+	content, err := toUnstructuredMap(obj)
+	Expect(err).ToNot(HaveOccurred())
+	result := &unstructured.Unstructured{Object: content}
+	result.SetGroupVersionKind(gvk)
+	return result
+}
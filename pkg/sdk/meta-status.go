@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/api"
+)
+
+// UnspecifiedReason is the reason of the metav1.Condition converted from a condition without reason, metav1.Condition
+// requires one
+const UnspecifiedReason = "Unspecified"
+
+// IsMetaUpgrading checks whether cr status represents upgrade in progress
+func IsMetaUpgrading(crStatus *api.MetaStatus) bool {
+	deploying := crStatus.Phase == api.PhaseDeploying
+	return (crStatus.ObservedVersion != "" || !deploying) && crStatus.ObservedVersion != crStatus.TargetVersion
+}
+
+// MarkMetaCrHealthyMessage is the MarkCrHealthyMessage equivalent for MetaStatus
+func MarkMetaCrHealthyMessage(cr client.Object, crStatus *api.MetaStatus, reason, message string, recorder record.EventRecorder) {
+	setMetaConditions(cr, crStatus, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse, v1.ConditionAvailable, reason, message)
+	recorder.Event(cr, corev1.EventTypeNormal, reason, message)
+}
+
+// MarkMetaCrUpgradeHealingDegraded is the MarkCrUpgradeHealingDegraded equivalent for MetaStatus
+func MarkMetaCrUpgradeHealingDegraded(cr client.Object, crStatus *api.MetaStatus, reason, message string, recorder record.EventRecorder) {
+	setMetaConditions(cr, crStatus, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionTrue, v1.ConditionDegraded, reason, message)
+	recorder.Event(cr, corev1.EventTypeNormal, reason, message)
+}
+
+// MarkMetaCrFailed is the MarkCrFailed equivalent for MetaStatus
+func MarkMetaCrFailed(cr client.Object, crStatus *api.MetaStatus, reason, message string, recorder record.EventRecorder) {
+	setMetaConditions(cr, crStatus, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue, v1.ConditionDegraded, reason, message)
+	recorder.Event(cr, corev1.EventTypeWarning, reason, message)
+}
+
+// MarkMetaCrFailedHealing is the MarkCrFailedHealing equivalent for MetaStatus
+func MarkMetaCrFailedHealing(cr client.Object, crStatus *api.MetaStatus, reason, message string, recorder record.EventRecorder) {
+	setMetaConditions(cr, crStatus, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue, v1.ConditionDegraded, reason, message)
+	recorder.Event(cr, corev1.EventTypeWarning, reason, message)
+}
+
+// MarkMetaCrDeploying is the MarkCrDeploying equivalent for MetaStatus
+func MarkMetaCrDeploying(cr client.Object, crStatus *api.MetaStatus, reason, message string, recorder record.EventRecorder) {
+	setMetaConditions(cr, crStatus, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse, v1.ConditionProgressing, reason, message)
+	recorder.Event(cr, corev1.EventTypeNormal, reason, message)
+}
+
+// setMetaConditions sets the Available, Progressing and Degraded conditions computed from the generation of the cr.
+// The message explains the condition of messageType, the reason applies to all of them since metav1.Condition requires
+// one.
+func setMetaConditions(cr client.Object, crStatus *api.MetaStatus, available, progressing, degraded metav1.ConditionStatus, messageType v1.ConditionType, reason, message string) {
+	for _, c := range []struct {
+		conditionType v1.ConditionType
+		status        metav1.ConditionStatus
+	}{
+		{v1.ConditionAvailable, available},
+		{v1.ConditionProgressing, progressing},
+		{v1.ConditionDegraded, degraded},
+	} {
+		condition := metav1.Condition{
+			Type:               string(c.conditionType),
+			Status:             c.status,
+			ObservedGeneration: cr.GetGeneration(),
+			Reason:             reason,
+		}
+		if c.conditionType == messageType {
+			condition.Message = message
+		}
+		meta.SetStatusCondition(&crStatus.Conditions, condition)
+	}
+}
+
+// ConditionToMeta converts the openshift condition into a metav1.Condition computed from given generation
+func ConditionToMeta(condition v1.Condition, generation int64) metav1.Condition {
+	reason := condition.Reason
+	if reason == "" {
+		reason = UnspecifiedReason
+	}
+	return metav1.Condition{
+		Type:               string(condition.Type),
+		Status:             metav1.ConditionStatus(condition.Status),
+		ObservedGeneration: generation,
+		LastTransitionTime: condition.LastTransitionTime,
+		Reason:             reason,
+		Message:            condition.Message,
+	}
+}
+
+// ConditionFromMeta converts the metav1.Condition into an openshift condition
+func ConditionFromMeta(condition metav1.Condition) v1.Condition {
+	reason := condition.Reason
+	if reason == UnspecifiedReason {
+		reason = ""
+	}
+	return v1.Condition{
+		Type:               v1.ConditionType(condition.Type),
+		Status:             corev1.ConditionStatus(condition.Status),
+		LastTransitionTime: condition.LastTransitionTime,
+		Reason:             reason,
+		Message:            condition.Message,
+	}
+}
+
+// StatusToMeta converts the status into out, the conditions get their generation from the condition generations
+func StatusToMeta(in *api.Status, out *api.MetaStatus) {
+	out.Phase = in.Phase
+	out.OperatorVersion = in.OperatorVersion
+	out.TargetVersion = in.TargetVersion
+	out.ObservedVersion = in.ObservedVersion
+	out.PhaseTransitionTime = in.PhaseTransitionTime.DeepCopy()
+	out.ObservedGeneration = in.ObservedGeneration
+	out.RelatedObjects = append([]corev1.ObjectReference(nil), in.RelatedObjects...)
+	out.Resources = append([]api.ResourceStatus(nil), in.Resources...)
+	out.Conditions = nil
+	for _, condition := range in.Conditions {
+		out.Conditions = append(out.Conditions, ConditionToMeta(condition, in.ConditionGenerations[string(condition.Type)]))
+	}
+}
+
+// StatusFromMeta converts the meta status into out, the generations of the conditions into the condition generations
+func StatusFromMeta(in *api.MetaStatus, out *api.Status) {
+	out.Phase = in.Phase
+	out.OperatorVersion = in.OperatorVersion
+	out.TargetVersion = in.TargetVersion
+	out.ObservedVersion = in.ObservedVersion
+	out.PhaseTransitionTime = in.PhaseTransitionTime.DeepCopy()
+	out.ObservedGeneration = in.ObservedGeneration
+	out.RelatedObjects = append([]corev1.ObjectReference(nil), in.RelatedObjects...)
+	out.Resources = append([]api.ResourceStatus(nil), in.Resources...)
+	out.Conditions = nil
+	out.ConditionGenerations = nil
+	for _, condition := range in.Conditions {
+		out.Conditions = append(out.Conditions, ConditionFromMeta(condition))
+		if out.ConditionGenerations == nil {
+			out.ConditionGenerations = map[string]int64{}
+		}
+		out.ConditionGenerations[condition.Type] = condition.ObservedGeneration
+	}
+}
@@ -0,0 +1,107 @@
+package sdk_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	v12 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("CR meta status", func() {
+	var recorder = &record.FakeRecorder{}
+
+	It("should be marked failed with the generation of the CR", func() {
+		cr := testcr.MetaConfig{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+		crStatus := sdkapi.MetaStatus{}
+
+		sdk.MarkMetaCrFailed(&cr, &crStatus, "TheReason", "the message", recorder)
+
+		Expect(crStatus.Conditions).To(HaveLen(3))
+		Expect(meta.IsStatusConditionFalse(crStatus.Conditions, string(v1.ConditionAvailable))).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(crStatus.Conditions, string(v1.ConditionProgressing))).To(BeTrue())
+		degraded := meta.FindStatusCondition(crStatus.Conditions, string(v1.ConditionDegraded))
+		Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal("TheReason"))
+		Expect(degraded.Message).To(Equal("the message"))
+		for _, condition := range crStatus.Conditions {
+			Expect(condition.ObservedGeneration).To(Equal(int64(3)))
+			Expect(condition.Reason).ToNot(BeEmpty())
+		}
+	})
+
+	It("should be marked healthy", func() {
+		cr := testcr.MetaConfig{}
+		crStatus := sdkapi.MetaStatus{}
+		sdk.MarkMetaCrDeploying(&cr, &crStatus, "DeployStarted", "Started", recorder)
+
+		sdk.MarkMetaCrHealthyMessage(&cr, &crStatus, "DeployCompleted", "Completed", recorder)
+
+		available := meta.FindStatusCondition(crStatus.Conditions, string(v1.ConditionAvailable))
+		Expect(available.Status).To(Equal(metav1.ConditionTrue))
+		Expect(available.Message).To(Equal("Completed"))
+		Expect(meta.IsStatusConditionFalse(crStatus.Conditions, string(v1.ConditionProgressing))).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(crStatus.Conditions, string(v1.ConditionDegraded))).To(BeTrue())
+	})
+
+	It("should convert the status both ways", func() {
+		transition := metav1.Now()
+		crStatus := sdkapi.Status{
+			Phase:               sdkapi.PhaseDeployed,
+			OperatorVersion:     "v1",
+			TargetVersion:       "v1",
+			ObservedVersion:     "v1",
+			PhaseTransitionTime: &transition,
+			ObservedGeneration:  2,
+			ConditionGenerations: map[string]int64{
+				string(v1.ConditionAvailable): 2,
+				string(v1.ConditionDegraded):  1,
+			},
+			Conditions: []v1.Condition{
+				{
+					Type:               v1.ConditionAvailable,
+					Status:             v12.ConditionTrue,
+					Reason:             "DeployCompleted",
+					Message:            "Deployment Completed",
+					LastTransitionTime: transition,
+				},
+				{
+					Type:               v1.ConditionDegraded,
+					Status:             v12.ConditionFalse,
+					LastTransitionTime: transition,
+				},
+			},
+		}
+
+		metaStatus := sdkapi.MetaStatus{}
+		sdk.StatusToMeta(&crStatus, &metaStatus)
+		Expect(metaStatus.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(metaStatus.ObservedGeneration).To(Equal(int64(2)))
+		Expect(metaStatus.Conditions).To(Equal([]metav1.Condition{
+			{
+				Type:               string(v1.ConditionAvailable),
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 2,
+				LastTransitionTime: transition,
+				Reason:             "DeployCompleted",
+				Message:            "Deployment Completed",
+			},
+			{
+				Type:               string(v1.ConditionDegraded),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 1,
+				LastTransitionTime: transition,
+				Reason:             sdk.UnspecifiedReason,
+			},
+		}))
+
+		converted := sdkapi.Status{}
+		sdk.StatusFromMeta(&metaStatus, &converted)
+		Expect(converted).To(Equal(crStatus))
+	})
+})
@@ -0,0 +1,99 @@
+package sdk
+
+import (
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CheckDaemonSetReady checks whether the daemon set is rolled out and its pods are available on every scheduled node
+func CheckDaemonSetReady(daemonSet *appsv1.DaemonSet) bool {
+	status := daemonSet.Status
+	return status.ObservedGeneration >= daemonSet.Generation &&
+		status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+		status.NumberReady == status.DesiredNumberScheduled &&
+		status.NumberUnavailable == 0
+}
+
+// CheckStatefulSetReady checks whether the stateful set is rolled out and all its replicas are ready
+func CheckStatefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	desiredReplicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desiredReplicas = *statefulSet.Spec.Replicas
+	}
+
+	status := statefulSet.Status
+	return status.ObservedGeneration >= statefulSet.Generation &&
+		status.Replicas == desiredReplicas &&
+		status.ReadyReplicas == desiredReplicas &&
+		status.UpdatedReplicas == desiredReplicas
+}
+
+// CheckJobComplete checks whether the job has completed successfully
+func CheckJobComplete(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAPIServiceAvailable checks whether the APIService reports the Available condition. The object may be either
+// the typed apiregistration.k8s.io APIService or its unstructured form.
+func CheckAPIServiceAvailable(apiService runtime.Object) (bool, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(apiService)
+	if err != nil {
+		return false, err
+	}
+
+	conditions, _, err := unstructured.NestedSlice(content, "status", "conditions")
+	if err != nil {
+		return false, err
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Available" {
+			return condition["status"] == string(v1.ConditionTrue), nil
+		}
+	}
+	return false, nil
+}
+
+// CheckValidatingWebhookCABundle checks whether every webhook calling a service has the CA bundle injected
+func CheckValidatingWebhookCABundle(config *admissionregistrationv1.ValidatingWebhookConfiguration) bool {
+	for _, webhook := range config.Webhooks {
+		if !hasCABundle(webhook.ClientConfig) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckMutatingWebhookCABundle checks whether every webhook calling a service has the CA bundle injected
+func CheckMutatingWebhookCABundle(config *admissionregistrationv1.MutatingWebhookConfiguration) bool {
+	for _, webhook := range config.Webhooks {
+		if !hasCABundle(webhook.ClientConfig) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckEndpointsReady checks whether the endpoints have at least one ready address
+func CheckEndpointsReady(endpoints *v1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCABundle(clientConfig admissionregistrationv1.WebhookClientConfig) bool {
+	// webhooks called by URL may rely on the system trust store
+	return clientConfig.Service == nil || len(clientConfig.CABundle) > 0
+}
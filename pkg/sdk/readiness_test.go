@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Readiness", func() {
+	DescribeTable("of DaemonSet", func(status appsv1.DaemonSetStatus, expected bool) {
+		daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: status}
+		Expect(CheckDaemonSetReady(daemonSet)).To(Equal(expected))
+	},
+		Entry("rolled out", appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3}, true),
+		Entry("not observed", appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3}, false),
+		Entry("crash-looping", appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 2, NumberUnavailable: 1}, false),
+		Entry("rolling out", appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 1, NumberReady: 3}, false),
+	)
+
+	DescribeTable("of StatefulSet", func(replicas *int32, status appsv1.StatefulSetStatus, expected bool) {
+		statefulSet := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: replicas}, Status: status}
+		Expect(CheckStatefulSetReady(statefulSet)).To(Equal(expected))
+	},
+		Entry("rolled out", &[]int32{2}[0], appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2}, true),
+		Entry("default replicas", nil, appsv1.StatefulSetStatus{Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1}, true),
+		Entry("not ready", &[]int32{2}[0], appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 2}, false),
+		Entry("rolling out", &[]int32{2}[0], appsv1.StatefulSetStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 1}, false),
+	)
+
+	DescribeTable("of Job", func(conditions []batchv1.JobCondition, expected bool) {
+		job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: conditions}}
+		Expect(CheckJobComplete(job)).To(Equal(expected))
+	},
+		Entry("complete", []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}, true),
+		Entry("running", nil, false),
+		Entry("failed", []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}, false),
+	)
+
+	DescribeTable("of APIService", func(conditions []interface{}, expected bool) {
+		apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiregistration.k8s.io/v1",
+			"kind":       "APIService",
+			"status":     map[string]interface{}{"conditions": conditions},
+		}}
+		Expect(CheckAPIServiceAvailable(apiService)).To(Equal(expected))
+	},
+		Entry("available", []interface{}{map[string]interface{}{"type": "Available", "status": "True"}}, true),
+		Entry("unavailable", []interface{}{map[string]interface{}{"type": "Available", "status": "False"}}, false),
+		Entry("without conditions", nil, false),
+	)
+
+	DescribeTable("of webhook configuration", func(clientConfig admissionregistrationv1.WebhookClientConfig, expected bool) {
+		validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{ClientConfig: clientConfig}},
+		}
+		Expect(CheckValidatingWebhookCABundle(validating)).To(Equal(expected))
+		mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{ClientConfig: clientConfig}},
+		}
+		Expect(CheckMutatingWebhookCABundle(mutating)).To(Equal(expected))
+	},
+		Entry("with injected caBundle", admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Name: "svc"}, CABundle: []byte("ca")}, true),
+		Entry("without caBundle", admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Name: "svc"}}, false),
+		Entry("called by URL", admissionregistrationv1.WebhookClientConfig{URL: &[]string{"https://webhook"}[0]}, true),
+	)
+
+	It("of Endpoints should require a ready address", func() {
+		endpoints := &corev1.Endpoints{Subsets: []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}}}
+		Expect(CheckEndpointsReady(endpoints)).To(BeFalse())
+		endpoints.Subsets[0].Addresses = []corev1.EndpointAddress{{IP: "10.0.0.2"}}
+		Expect(CheckEndpointsReady(endpoints)).To(BeTrue())
+	})
+})
@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// useServerSideApply checks whether managed resources are written with server-side apply
+func (r *Reconciler) useServerSideApply() bool {
+	return r.fieldManager != ""
+}
+
+// newApplyObject builds the configuration applied for desiredObj. Server-side apply removes every field the field
+// manager applied before but omits now, so the object has to carry everything the reconciler owns: the controller
+// reference, the recommended labels and the version labels already present on the current object.
+func (r *Reconciler) newApplyObject(cr client.Object, desiredObj, currentObj client.Object, operatorVersion string) (client.Object, error) {
+	applyObj := desiredObj.DeepCopyObject().(client.Object)
+
+	gvk, err := apiutil.GVKForObject(applyObj, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	applyObj.GetObjectKind().SetGroupVersionKind(gvk)
+	applyObj.SetResourceVersion("")
+	applyObj.SetManagedFields(nil)
+
+	createVersion := operatorVersion
+	if currentObj != nil {
+		if v, ok := currentObj.GetLabels()[r.createVersionLabel]; ok {
+			createVersion = v
+		}
+		if v, ok := currentObj.GetLabels()[r.updateVersionLabel]; ok {
+			sdk.SetLabel(r.updateVersionLabel, v, applyObj)
+		}
+	}
+	sdk.SetLabel(r.createVersionLabel, createVersion, applyObj)
+	r.setRecommendedLabels(cr, applyObj)
+
+	if err = controllerutil.SetControllerReference(cr, applyObj, r.scheme); err != nil {
+		return nil, err
+	}
+
+	return applyObj, nil
+}
+
+// apply writes obj to the cluster with server-side apply, taking over any conflicting fields
+func (r *Reconciler) apply(ctx context.Context, obj client.Object, opts ...client.PatchOption) error {
+	opts = append(opts, client.FieldOwner(r.fieldManager), client.ForceOwnership)
+	return r.client.Patch(ctx, obj, client.Apply, opts...)
+}
+
+// dryRunApply returns the object that applying applyObj would produce, normalized so that it can be compared with
+// currentObj: the status and server-maintained metadata are taken over from currentObj.
+func (r *Reconciler) dryRunApply(ctx context.Context, applyObj, currentObj client.Object) (client.Object, error) {
+	result := applyObj.DeepCopyObject().(client.Object)
+	if err := r.apply(ctx, result, client.DryRunAll); err != nil {
+		return nil, err
+	}
+
+	result, err := sdk.StripStatusFromObject(result)
+	if err != nil {
+		return nil, err
+	}
+
+	result.GetObjectKind().SetGroupVersionKind(currentObj.GetObjectKind().GroupVersionKind())
+	result.SetResourceVersion(currentObj.GetResourceVersion())
+	result.SetGeneration(currentObj.GetGeneration())
+	result.SetManagedFields(currentObj.GetManagedFields())
+	result.SetCreationTimestamp(currentObj.GetCreationTimestamp())
+
+	return result, nil
+}
+
+// withCallbackChanges returns applyObj with the changes the callbacks made to currentObj since original, which would be
+// lost otherwise since applyObj is written instead of currentObj
+func withCallbackChanges(applyObj, original, currentObj client.Object) (client.Object, error) {
+	originalBytes, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	currentBytes, err := json.Marshal(currentObj)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.CreateMergePatch(originalBytes, currentBytes)
+	if err != nil {
+		return nil, err
+	}
+	if string(patch) == "{}" {
+		return applyObj, nil
+	}
+
+	applyBytes, err := json.Marshal(applyObj)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := jsonpatch.MergePatch(applyBytes, patch)
+	if err != nil {
+		return nil, err
+	}
+	result := sdk.NewDefaultInstance(applyObj)
+	if err = json.Unmarshal(merged, result); err != nil {
+		return nil, err
+	}
+	result.GetObjectKind().SetGroupVersionKind(applyObj.GetObjectKind().GroupVersionKind())
+	return result, nil
+}
+
+// setLastAppliedConfiguration records the applied configuration of obj unless server-side apply tracks it instead
+func (r *Reconciler) setLastAppliedConfiguration(ctx context.Context, cr client.Object, obj client.Object) error {
+	if r.useServerSideApply() {
+		return nil
+	}
+	return r.lastAppliedStore(cr).Save(ctx, obj)
+}
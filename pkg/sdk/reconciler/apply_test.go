@@ -0,0 +1,178 @@
+package reconciler_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+const fieldManager = "test-operator"
+
+var _ = Describe("Server-side apply", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	It("should create resources without last applied configuration", func() {
+		args := createApplyArgs(version)
+		doReconcile(args)
+
+		for _, r := range getAllResources(args.config) {
+			storedObj, err := getObject(args.client, r)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedObj.GetAnnotations()).ToNot(HaveKey("last-applied-config"))
+			Expect(storedObj.GetLabels()).To(HaveKeyWithValue(createVersionLabel, version))
+			Expect(metav1.IsControlledBy(storedObj, args.config)).To(BeTrue())
+		}
+	})
+
+	It("should restore modified resource and record the update", func() {
+		args := createApplyArgs(version)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		deployment, err := getDeployment(args.client, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Spec.Template.Spec.Containers[0].Env = nil
+		Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+		var states []callbacks.ReconcileState
+		invokeCallbacks = func(_ interface{}, state callbacks.ReconcileState, _ client.Object, _ client.Object) error {
+			states = append(states, state)
+			return nil
+		}
+		doReconcile(args)
+
+		deployment, err = getDeployment(args.client, deployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+		Expect(deployment.GetLabels()).To(HaveKeyWithValue("update-version", version))
+		Expect(states).To(Equal([]callbacks.ReconcileState{
+			callbacks.ReconcileStatePostRead,
+			callbacks.ReconcileStatePreUpdate,
+			callbacks.ReconcileStatePostUpdate,
+		}))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Normal UpdateResourceSuccess Successfully updated resource *v1.Deployment " + testcr.OperatorDeploymentName))
+	})
+
+	It("should apply the changes of the pre-update callbacks", func() {
+		args := createApplyArgs(version)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		deployment, err := getDeployment(args.client, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Spec.Template.Spec.Containers[0].Env = nil
+		Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+		invokeCallbacks = func(_ interface{}, state callbacks.ReconcileState, _ client.Object, currentObj client.Object) error {
+			if state == callbacks.ReconcileStatePreUpdate {
+				currentObj.(*appsv1.Deployment).Spec.MinReadySeconds = 7
+			}
+			return nil
+		}
+		doReconcile(args)
+
+		deployment, err = getDeployment(args.client, deployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+		Expect(deployment.Spec.MinReadySeconds).To(BeEquivalentTo(7))
+	})
+
+	It("should not update unchanged resources", func() {
+		args := createApplyArgs(version)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		drainEvents(args.recorder)
+
+		doReconcile(args)
+
+		for _, event := range drainEvents(args.recorder) {
+			Expect(event).ToNot(ContainSubstring("UpdateResource"))
+		}
+	})
+})
+
+func createApplyArgs(version string) *args {
+	args := createArgs(version)
+	args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{Patch: applyAsMergePatch})
+	args.reconciler = createReconciler(args.client, args.client.Scheme(), args.recorder).
+		WithController(args.mockController).
+		WithServerSideApply(fieldManager)
+	return args
+}
+
+// applyAsMergePatch emulates server-side apply, which the fake client does not support, with JSON merge patches
+func applyAsMergePatch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(opts)
+	dryRun := len(patchOptions.DryRun) > 0
+	Expect(patchOptions.FieldManager).To(Equal(fieldManager))
+	Expect(*patchOptions.Force).To(BeTrue())
+
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+
+	current := sdk.NewDefaultInstance(obj)
+	if err = c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if !errors.IsNotFound(err) || dryRun {
+			return err
+		}
+		return c.Create(ctx, obj)
+	}
+
+	if !dryRun {
+		return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+	}
+
+	currentBytes, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	merged, err := jsonpatch.MergePatch(currentBytes, data)
+	if err != nil {
+		return err
+	}
+	result := sdk.NewDefaultInstance(obj)
+	if err = json.Unmarshal(merged, result); err != nil {
+		return err
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(result).Elem())
+	return nil
+}
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package reconciler
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +36,18 @@ func NewReconciler(crManager CrManager, log logr.Logger, client client.Client, c
 		watch:                         watch,
 		preCreate:                     preCreate,
 		subresourceEnabled:            subresourceEnabled,
+		annotationPrefix:              DefaultAnnotationPrefix,
+		resourceConcurrency:           1,
+		downgradePolicy:               DowngradeRefuse,
+		readinessCheckers:             DefaultReadinessCheckers(),
+		healthExpressions:             newHealthExpressions(),
+		healthExpressionsByGVK:        map[schema.GroupVersionKind]string{},
+		mergeStrategies:               DefaultMergeStrategies(),
+		recreatePolicy:                RecreateNever,
+		recreatableKinds:              DefaultRecreatableKinds(),
+		comparisonMode:                CompareExact,
+		lastAppliedStorage:            LastAppliedAnnotation,
+		relatedObjectsDebounce:        DefaultRelatedObjectsDebounce,
 	}
 }
 
@@ -56,6 +71,14 @@ func (r *Reconciler) WithWatching(watching bool) *Reconciler {
 
 // WithPerishablesSynchronizer sets PerishablesSynchronizer, which must not be nil
 func (r *Reconciler) WithPerishablesSynchronizer(syncPerishables PerishablesSynchronizer) *Reconciler {
+	r.syncPerishables = func(_ context.Context, cr client.Object, logger logr.Logger) error {
+		return syncPerishables(cr, logger)
+	}
+	return r
+}
+
+// WithContextPerishablesSynchronizer sets ContextPerishablesSynchronizer, which must not be nil
+func (r *Reconciler) WithContextPerishablesSynchronizer(syncPerishables ContextPerishablesSynchronizer) *Reconciler {
 	r.syncPerishables = syncPerishables
 	return r
 }
@@ -68,6 +91,14 @@ func (r *Reconciler) WithControllerConfigUpdater(updateConfig ControllerConfigUp
 
 // WithSanityChecker sets SanityChecker
 func (r *Reconciler) WithSanityChecker(checkSanity SanityChecker) *Reconciler {
+	r.checkSanity = func(_ context.Context, cr client.Object, logger logr.Logger) (*reconcile.Result, error) {
+		return checkSanity(cr, logger)
+	}
+	return r
+}
+
+// WithContextSanityChecker sets ContextSanityChecker
+func (r *Reconciler) WithContextSanityChecker(checkSanity ContextSanityChecker) *Reconciler {
 	r.checkSanity = checkSanity
 	return r
 }
@@ -80,6 +111,17 @@ func (r *Reconciler) WithWatchRegistrator(watch WatchRegistrator) *Reconciler {
 
 // WithPreCreateHook sets PreCreateHook
 func (r *Reconciler) WithPreCreateHook(preCreate PreCreateHook) *Reconciler {
+	if preCreate == nil {
+		panic("Pre create hook mustn't be nil")
+	}
+	r.preCreate = func(_ context.Context, cr client.Object) error {
+		return preCreate(cr)
+	}
+	return r
+}
+
+// WithContextPreCreateHook sets ContextPreCreateHook
+func (r *Reconciler) WithContextPreCreateHook(preCreate ContextPreCreateHook) *Reconciler {
 	if preCreate == nil {
 		panic("Pre create hook mustn't be nil")
 	}
@@ -87,7 +129,214 @@ func (r *Reconciler) WithPreCreateHook(preCreate PreCreateHook) *Reconciler {
 	return r
 }
 
-func preCreate(_ client.Object) error {
+// WithServerSideApply makes the Reconciler write managed resources with server-side apply using given field manager.
+// Conflicting fields are taken over and no last applied configuration annotation is maintained.
+func (r *Reconciler) WithServerSideApply(fieldManager string) *Reconciler {
+	if fieldManager == "" {
+		panic("Field manager mustn't be empty")
+	}
+	r.fieldManager = fieldManager
+	return r
+}
+
+// WithAnnotationPrefix sets the prefix of the annotations recognized by the Reconciler, i.e. the apply wave annotation
+func (r *Reconciler) WithAnnotationPrefix(prefix string) *Reconciler {
+	if prefix == "" {
+		panic("Annotation prefix mustn't be empty")
+	}
+	r.annotationPrefix = prefix
+	return r
+}
+
+// WithResourceConcurrency makes the Reconciler reconcile up to workers managed resources of an apply wave concurrently.
+// Callbacks of a single resource are still invoked in order, but the callbacks of different resources may run in
+// parallel and must be safe for concurrent use. A value of 1 (the default) reconciles the resources sequentially.
+func (r *Reconciler) WithResourceConcurrency(workers int) *Reconciler {
+	if workers < 1 {
+		panic("Resource concurrency must be at least 1")
+	}
+	r.resourceConcurrency = workers
+	return r
+}
+
+// WithReadinessChecker registers the checker deciding whether managed resources of given GVK are ready, replacing the
+// default one if any. Not ready resources hold up the following apply waves and make the CR degraded.
+func (r *Reconciler) WithReadinessChecker(gvk schema.GroupVersionKind, checker ReadinessChecker) *Reconciler {
+	if checker == nil {
+		panic("Readiness checker mustn't be nil")
+	}
+	r.readinessCheckers[gvk] = checker
+	return r
+}
+
+// WithBuiltinReadinessCheckers registers the BuiltinReadinessCheckers of given GVKs, all of them if none is given
+func (r *Reconciler) WithBuiltinReadinessCheckers(gvks ...schema.GroupVersionKind) *Reconciler {
+	builtin := BuiltinReadinessCheckers()
+	if len(gvks) == 0 {
+		for gvk, checker := range builtin {
+			r.readinessCheckers[gvk] = checker
+		}
+		return r
+	}
+	for _, gvk := range gvks {
+		checker, ok := builtin[gvk]
+		if !ok {
+			panic(fmt.Sprintf("No built-in readiness checker for %s", gvk))
+		}
+		r.readinessCheckers[gvk] = checker
+	}
+	return r
+}
+
+// WithHealthExpression sets the CEL expression that has to evaluate to true for the managed resources of given GVK to be
+// ready, in addition to their readiness checker. The health expression annotation of a resource takes precedence.
+func (r *Reconciler) WithHealthExpression(gvk schema.GroupVersionKind, expression string) *Reconciler {
+	if _, err := r.healthExpressions.compile(expression); err != nil {
+		panic(err.Error())
+	}
+	r.healthExpressionsByGVK[gvk] = expression
+	return r
+}
+
+// WithDeployDeadline sets the time a deployment has to complete within, counted from the phase transition time of the
+// CR. When it expires, the Degraded condition of the CR gets the DeployTimeout reason and, with DeployDeadlineFail, the
+// CR is moved to the Error phase.
+func (r *Reconciler) WithDeployDeadline(deadline time.Duration, action DeployDeadlineAction) *Reconciler {
+	if deadline <= 0 {
+		panic("Deploy deadline must be positive")
+	}
+	if action != DeployDeadlineReport && action != DeployDeadlineFail {
+		panic(fmt.Sprintf("Unknown deploy deadline action %q", action))
+	}
+	r.deployDeadline = deadline
+	r.deployDeadlineAction = action
+	return r
+}
+
+// WithUpgradeDeadline sets the time an upgrade has to complete within, counted from the phase transition time of the
+// CR. When it expires, the Degraded condition of the CR gets the UpgradeTimeout reason with UpgradeDeadlineReport,
+// otherwise the CR is moved to the Error phase, after restoring the resources of the previous version from a snapshot
+// with UpgradeDeadlineRollback. The snapshot of the last successfully deployed version is kept compressed in the
+// <cr name>-lifecycle-snapshot Secret, in the namespace of the CR or the one set by WithSnapshotNamespace.
+func (r *Reconciler) WithUpgradeDeadline(deadline time.Duration, action UpgradeDeadlineAction) *Reconciler {
+	if deadline <= 0 {
+		panic("Upgrade deadline must be positive")
+	}
+	if action != UpgradeDeadlineReport && action != UpgradeDeadlineFail && action != UpgradeDeadlineRollback {
+		panic(fmt.Sprintf("Unknown upgrade deadline action %q", action))
+	}
+	r.upgradeDeadline = deadline
+	r.upgradeDeadlineAction = action
+	return r
+}
+
+// WithRelatedObjectsDebounce sets the minimal interval between status writes caused only by objects missing from the
+// related objects of the CR, DefaultRelatedObjectsDebounce by default; with 0 they are written right away
+func (r *Reconciler) WithRelatedObjectsDebounce(interval time.Duration) *Reconciler {
+	if interval < 0 {
+		panic("Related objects debounce interval mustn't be negative")
+	}
+	r.relatedObjectsDebounce = interval
+	return r
+}
+
+// WithResourceStatus enables the resource status of the CR: an entry per managed resource with the result of its last
+// reconciliation and its readiness, maintained by ReconcileUpdate and CheckDegraded. At most limit entries are kept,
+// the failed and not ready resources first.
+func (r *Reconciler) WithResourceStatus(limit int) *Reconciler {
+	if limit <= 0 {
+		panic("Resource status limit must be positive")
+	}
+	r.resourceStatusLimit = limit
+	return r
+}
+
+// WithSnapshotNamespace sets the namespace of the snapshot used to roll back failed upgrades; required for cluster
+// scoped CRs
+func (r *Reconciler) WithSnapshotNamespace(namespace string) *Reconciler {
+	r.snapshotNamespace = namespace
+	return r
+}
+
+// WithMergeStrategy sets how the desired state of the managed resources of given GVK is merged into their live state,
+// replacing the default strategy if any. It has no effect in the server-side apply mode.
+func (r *Reconciler) WithMergeStrategy(gvk schema.GroupVersionKind, strategy MergeStrategy) *Reconciler {
+	if !strategy.valid() {
+		panic(fmt.Sprintf("Unknown merge strategy %q", strategy))
+	}
+	r.mergeStrategies[gvk] = strategy
+	return r
+}
+
+// WithRecreatePolicy sets whether managed resources whose update is refused because of a changed immutable field are
+// deleted and created again, RecreateNever by default. Only the kinds returned by DefaultRecreatableKinds and the ones
+// registered with WithRecreatableKind are recreated.
+func (r *Reconciler) WithRecreatePolicy(policy RecreatePolicy) *Reconciler {
+	switch policy {
+	case RecreateNever, RecreateDeleteDependents, RecreateOrphanDependents:
+		r.recreatePolicy = policy
+	default:
+		panic(fmt.Sprintf("Unknown recreate policy %q", policy))
+	}
+	return r
+}
+
+// WithRecreatableKind allows the managed resources of given GVK to be recreated under the recreate policy
+func (r *Reconciler) WithRecreatableKind(gvk schema.GroupVersionKind) *Reconciler {
+	r.recreatableKinds[gvk] = true
+	return r
+}
+
+// WithComparisonMode sets how the live state of managed resources is compared with their updated state to decide
+// whether to update them, CompareExact by default. CompareSchemeDefaults needs the defaulting functions to be
+// registered in the scheme of the reconciler. It has no effect in the server-side apply mode, which always compares
+// with the outcome of a dry-run apply.
+func (r *Reconciler) WithComparisonMode(mode ComparisonMode) *Reconciler {
+	switch mode {
+	case CompareExact, CompareSchemeDefaults, CompareServerDryRun:
+		r.comparisonMode = mode
+	default:
+		panic(fmt.Sprintf("Unknown comparison mode %q", mode))
+	}
+	return r
+}
+
+// WithLastAppliedStorage sets where the last applied configuration of the managed resources is kept,
+// LastAppliedAnnotation by default. The storage can be switched on existing deployments: the snapshot storage reads the
+// configurations recorded in the annotation, while the annotation storages fall back to a two-way merge for the
+// resources whose configuration was recorded in the snapshot, until they are updated again.
+func (r *Reconciler) WithLastAppliedStorage(storage LastAppliedStorage) *Reconciler {
+	switch storage {
+	case LastAppliedAnnotation, LastAppliedCompressedAnnotation, LastAppliedHashSnapshot:
+		r.lastAppliedStorage = storage
+	default:
+		panic(fmt.Sprintf("Unknown last applied storage %q", storage))
+	}
+	return r
+}
+
+// WithDowngradePolicy sets how the Reconciler handles an operator older than the deployed version, DowngradeRefuse by
+// default
+func (r *Reconciler) WithDowngradePolicy(policy DowngradePolicy) *Reconciler {
+	switch policy {
+	case DowngradeRefuse, DowngradeAllowWithDowngradePath, DowngradeAllowWithinPatch:
+		r.downgradePolicy = policy
+	default:
+		panic(fmt.Sprintf("Unknown downgrade policy %q", policy))
+	}
+	return r
+}
+
+// WithDowngradeHook registers a DowngradeHook, executed when an allowed downgrade starts
+func (r *Reconciler) WithDowngradeHook(hook DowngradeHook) *Reconciler {
+	if hook == nil {
+		panic("Downgrade hook mustn't be nil")
+	}
+	r.downgradeHooks = append(r.downgradeHooks, hook)
+	return r
+}
+
+func preCreate(_ context.Context, _ client.Object) error {
 	return nil
 }
 
@@ -95,7 +344,7 @@ func watch() error {
 	return nil
 }
 
-func checkSanity(_ client.Object, _ logr.Logger) (*reconcile.Result, error) {
+func checkSanity(_ context.Context, _ client.Object, _ logr.Logger) (*reconcile.Result, error) {
 	return nil, nil
 }
 
@@ -103,6 +352,6 @@ func updateControllerConfiguration(_ client.Object) error {
 	return nil
 }
 
-func syncPerishables(cr client.Object, logger logr.Logger) error {
+func syncPerishables(_ context.Context, _ client.Object, _ logr.Logger) error {
 	return nil
 }
@@ -0,0 +1,78 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// ComparisonMode decides how the live state of a managed resource is compared with the state it would be updated to
+type ComparisonMode string
+
+const (
+	// CompareExact updates the resource whenever the states differ, the default
+	CompareExact ComparisonMode = "exact"
+	// CompareSchemeDefaults runs the defaulting functions registered in the scheme on the updated state before the
+	// comparison, so that fields the API server defaults don't cause updates. The client-go scheme registers no
+	// defaulting functions, the operator has to register them, e.g. with RegisterDefaults of the k8s.io/kubernetes
+	// API packages or AddTypeDefaultingFunc; without them the mode behaves like CompareExact.
+	CompareSchemeDefaults ComparisonMode = "scheme-defaults"
+	// CompareServerDryRun compares with the outcome of a dry-run update, which the API server defaults and
+	// normalises, at the price of an additional request whenever the states differ
+	CompareServerDryRun ComparisonMode = "server-dry-run"
+)
+
+// AvoidedUpdatesMetricName is the name of the counter of the updates the comparison mode avoided, labelled by the mode
+const AvoidedUpdatesMetricName = "lifecycle_reconciler_avoided_updates_total"
+
+var avoidedUpdates = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: AvoidedUpdatesMetricName,
+		Help: "Number of managed resource updates avoided by normalising the updated state before the comparison",
+	},
+	[]string{"mode"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(avoidedUpdates)
+}
+
+// compareStates checks whether updating currentObj to updatedObj would change anything. normalised reports that the
+// states only became equal after the normalisation of the comparison mode.
+func (r *Reconciler) compareStates(ctx context.Context, currentObj, updatedObj client.Object) (equal bool, normalised bool, err error) {
+	if reflect.DeepEqual(currentObj, updatedObj) {
+		return true, false, nil
+	}
+
+	var normalisedObj client.Object
+	switch r.comparisonMode {
+	case CompareSchemeDefaults:
+		normalisedObj = updatedObj.DeepCopyObject().(client.Object)
+		r.scheme.Default(normalisedObj)
+	case CompareServerDryRun:
+		normalisedObj = updatedObj.DeepCopyObject().(client.Object)
+		if err = r.client.Update(ctx, normalisedObj, client.DryRunAll); err != nil {
+			// let the actual update report the error
+			return false, false, nil
+		}
+		if normalisedObj, err = sdk.StripStatusFromObject(normalisedObj); err != nil {
+			return false, false, err
+		}
+		// bookkeeping the dry-run may have changed
+		normalisedObj.SetResourceVersion(currentObj.GetResourceVersion())
+		normalisedObj.SetGeneration(currentObj.GetGeneration())
+		normalisedObj.SetManagedFields(currentObj.GetManagedFields())
+	default:
+		return false, false, nil
+	}
+
+	if !reflect.DeepEqual(currentObj, normalisedObj) {
+		return false, false, nil
+	}
+	return true, true, nil
+}
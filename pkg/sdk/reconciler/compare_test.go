@@ -0,0 +1,135 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Comparison modes", func() {
+	var updates int
+
+	BeforeEach(func() {
+		updates = 0
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// createDefaultingArgs creates a client defaulting the containers of deployments, as the API server does
+	createDefaultingArgs := func(mode reconciler.ComparisonMode) *args {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(extv1.AddToScheme(s)).To(Succeed())
+		Expect(testcr.AddToScheme(s)).To(Succeed())
+		s.AddTypeDefaultingFunc(&appsv1.Deployment{}, defaultDeployment)
+
+		args := createArgs(version)
+		args.client = interceptor.NewClient(createClient(s, args.config).(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				defaultDeployment(obj)
+				return c.Create(ctx, obj, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				_, isDeployment := obj.(*appsv1.Deployment)
+				defaultDeployment(obj)
+				if isDeployment && len((&client.UpdateOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					updates++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		args.reconciler = createReconcilerWithCrManager(&testcr.ConfigCrManager{}, args.client, s, args.recorder).
+			WithController(args.mockController).
+			WithComparisonMode(mode)
+		return args
+	}
+
+	avoided := func(mode reconciler.ComparisonMode) float64 {
+		families, err := metrics.Registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != reconciler.AvoidedUpdatesMetricName {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if metric.GetLabel()[0].GetValue() == string(mode) {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+
+	It("should update defaulted resources on every reconcile in exact mode", func() {
+		args := createDefaultingArgs(reconciler.CompareExact)
+		doReconcile(args)
+
+		doReconcile(args)
+		doReconcile(args)
+
+		Expect(updates).To(Equal(2))
+	})
+
+	for _, mode := range []reconciler.ComparisonMode{reconciler.CompareSchemeDefaults, reconciler.CompareServerDryRun} {
+		mode := mode
+		It("should not update defaulted resources in "+string(mode)+" mode", func() {
+			args := createDefaultingArgs(mode)
+			doReconcile(args)
+			before := avoided(mode)
+
+			doReconcile(args)
+			doReconcile(args)
+
+			Expect(updates).To(BeZero())
+			Expect(avoided(mode) - before).To(BeEquivalentTo(2))
+		})
+
+		It("should still update drifted resources in "+string(mode)+" mode", func() {
+			args := createDefaultingArgs(mode)
+			doReconcile(args)
+
+			deployment := &appsv1.Deployment{}
+			Expect(args.client.Get(context.TODO(), client.ObjectKey{Namespace: testcr.Namespace, Name: testcr.OperatorDeploymentName}, deployment)).To(Succeed())
+			deployment.Spec.Template.Spec.Containers[0].Image = "other"
+			Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+			updates = 0
+
+			doReconcile(args)
+
+			Expect(updates).To(Equal(1))
+			Expect(args.client.Get(context.TODO(), client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("image"))
+		})
+	}
+
+	It("should reject unknown mode", func() {
+		args := createArgs(version)
+		Expect(func() { args.reconciler.WithComparisonMode("semantic") }).To(Panic())
+	})
+})
+
+func defaultDeployment(obj interface{}) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+	for i := range deployment.Spec.Template.Spec.Containers {
+		if deployment.Spec.Template.Spec.Containers[i].TerminationMessagePath == "" {
+			deployment.Spec.Template.Spec.Containers[i].TerminationMessagePath = "/dev/termination-log"
+		}
+	}
+}
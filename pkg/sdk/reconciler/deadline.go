@@ -0,0 +1,146 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// DeployDeadlineAction is what the Reconciler does with a deployment not completed within the deadline
+type DeployDeadlineAction string
+
+const (
+	// DeployDeadlineReport sets the DeployTimeout reason on the Degraded condition of the CR, the deployment goes on
+	DeployDeadlineReport DeployDeadlineAction = "Report"
+	// DeployDeadlineFail moves the CR to the Error phase, which it leaves for the Deployed one once its resources are
+	// ready
+	DeployDeadlineFail DeployDeadlineAction = "Fail"
+)
+
+const (
+	// DeployTimeoutReason is the reason of the Degraded condition of a CR not deployed within the deploy deadline
+	DeployTimeoutReason = "DeployTimeout"
+	// UpgradeTimeoutReason is the reason of the Degraded condition of a CR not upgraded within the upgrade deadline
+	UpgradeTimeoutReason = upgradeTimeout
+)
+
+// phaseStartTime returns the time the CR entered its current phase. The status written by older versions lacks it, the
+// last transition of the Progressing condition is used instead then.
+func phaseStartTime(status *sdkapi.Status) (time.Time, bool) {
+	if status.PhaseTransitionTime != nil {
+		return status.PhaseTransitionTime.Time, true
+	}
+	progressing := conditions.FindStatusCondition(status.Conditions, conditions.ConditionProgressing)
+	if progressing == nil || progressing.Status != corev1.ConditionTrue {
+		return time.Time{}, false
+	}
+	return progressing.LastTransitionTime.Time, true
+}
+
+// deployTimeLeft returns the time left until the deploy deadline and whether a deployment with a deadline is in progress
+func (r *Reconciler) deployTimeLeft(ctx context.Context, cr client.Object) (time.Duration, bool) {
+	status := r.status(ctx, cr)
+	if r.deployDeadline == 0 || status.Phase != sdkapi.PhaseDeploying {
+		return 0, false
+	}
+	started, ok := phaseStartTime(status)
+	if !ok {
+		return 0, false
+	}
+	return time.Until(started.Add(r.deployDeadline)), true
+}
+
+// deployTimeoutMessage describes the deployment not completed within the deadline
+func (r *Reconciler) deployTimeoutMessage() string {
+	return fmt.Sprintf("Deployment did not complete within %s", r.deployDeadline)
+}
+
+// deployTimedOut checks whether the cr was moved to the Error phase by the deploy deadline
+func (r *Reconciler) deployTimedOut(ctx context.Context, cr client.Object) bool {
+	status := r.status(ctx, cr)
+	if status.Phase != sdkapi.PhaseError {
+		return false
+	}
+	degraded := conditions.FindStatusCondition(status.Conditions, conditions.ConditionDegraded)
+	return degraded != nil && degraded.Reason == DeployTimeoutReason
+}
+
+// expired checks whether the time left until a deadline, if any, ran out
+func expired(left time.Duration, ok bool) bool {
+	return ok && left <= 0
+}
+
+// requeueBeforePhaseDeadline makes sure the CR is reconciled again when the deadline of its phase expires
+func (r *Reconciler) requeueBeforePhaseDeadline(ctx context.Context, cr client.Object, result reconcile.Result) reconcile.Result {
+	for _, timeLeft := range []func(context.Context, client.Object) (time.Duration, bool){r.deployTimeLeft, r.upgradeTimeLeft} {
+		if left, ok := timeLeft(ctx, cr); ok && left > 0 && (result.RequeueAfter == 0 || left < result.RequeueAfter) {
+			result.RequeueAfter = left
+		}
+	}
+	return result
+}
+
+// handleDeployDeadline reports or fails the deployment in progress if its deadline expired; the returned flag tells
+// whether it failed the deployment
+func (r *Reconciler) handleDeployDeadline(ctx context.Context, logger logr.Logger, cr client.Object) (bool, error) {
+	if !expired(r.deployTimeLeft(ctx, cr)) {
+		return false, nil
+	}
+	if r.deployDeadlineAction == DeployDeadlineReport {
+		return false, r.reportTimeout(ctx, logger, cr)
+	}
+
+	logger.Info("Deploy deadline exceeded", "deadline", r.deployDeadline)
+	sdk.MarkCrFailed(cr, r.status(ctx, cr), DeployTimeoutReason, r.deployTimeoutMessage(), r.recorder)
+	return true, r.CrUpdateStatusContext(ctx, sdkapi.PhaseError, cr)
+}
+
+// timeoutCondition returns the Degraded condition of a cr whose phase deadline expired, which CheckDegraded keeps, or
+// nil
+func (r *Reconciler) timeoutCondition(ctx context.Context, cr client.Object) *conditions.Condition {
+	status := r.status(ctx, cr)
+	if r.deployTimedOut(ctx, cr) {
+		return conditions.FindStatusCondition(status.Conditions, conditions.ConditionDegraded)
+	}
+
+	var reason, message string
+	switch {
+	case expired(r.deployTimeLeft(ctx, cr)) && r.deployDeadlineAction == DeployDeadlineReport:
+		reason, message = DeployTimeoutReason, r.deployTimeoutMessage()
+	case expired(r.upgradeTimeLeft(ctx, cr)) && r.upgradeDeadlineAction == UpgradeDeadlineReport:
+		reason, message = UpgradeTimeoutReason, r.upgradeTimeoutMessage(status)
+	default:
+		return nil
+	}
+	return &conditions.Condition{
+		Type:    conditions.ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reportTimeout sets the Degraded condition of a cr whose phase deadline expired with the Report action, unless done
+// already; the phase is left unchanged
+func (r *Reconciler) reportTimeout(ctx context.Context, logger logr.Logger, cr client.Object) error {
+	timeout := r.timeoutCondition(ctx, cr)
+	status := r.status(ctx, cr)
+	degraded := conditions.FindStatusCondition(status.Conditions, conditions.ConditionDegraded)
+	if timeout == nil || degraded != nil && degraded.Status == timeout.Status && degraded.Reason == timeout.Reason && degraded.Message == timeout.Message {
+		return nil
+	}
+
+	logger.Info("Phase deadline exceeded", "phase", status.Phase, "reason", timeout.Reason)
+	conditions.SetStatusCondition(&status.Conditions, *timeout)
+	r.recorder.Event(cr, corev1.EventTypeWarning, timeout.Reason, timeout.Message)
+	return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
@@ -0,0 +1,123 @@
+package reconciler_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Deploy deadline", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// startDeploy starts the deployment of the CR, which never becomes ready on its own
+	startDeploy := func(action reconciler.DeployDeadlineAction) *args {
+		args := createArgs(version)
+		args.reconciler = createReconcilerWithCrManager(&testcr.ConfigCrManager{}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithDeployDeadline(time.Minute, action)
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		return args
+	}
+
+	expireDeploy := func(args *args) {
+		expired := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+		args.config.Status.PhaseTransitionTime = &expired
+		Expect(args.client.Status().Update(context.TODO(), args.config)).To(Succeed())
+	}
+
+	It("should record the time the CR entered its phase", func() {
+		args := startDeploy(reconciler.DeployDeadlineReport)
+		Expect(args.config.Status.PhaseTransitionTime).ToNot(BeNil())
+		deploying := *args.config.Status.PhaseTransitionTime
+
+		doReconcile(args)
+		Expect(*args.config.Status.PhaseTransitionTime).To(Equal(deploying))
+
+		expireDeploy(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(args.config.Status.PhaseTransitionTime.After(time.Now().Add(-time.Minute))).To(BeTrue())
+	})
+
+	It("should requeue until the deadline", func() {
+		args := startDeploy(reconciler.DeployDeadlineReport)
+
+		result, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), args.version, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+	})
+
+	It("should report deployment after the deadline and let it go on", func() {
+		args := startDeploy(reconciler.DeployDeadlineReport)
+		drainEvents(args.recorder)
+		expireDeploy(args)
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		degraded := conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded)
+		Expect(degraded.Status).To(Equal(corev1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(reconciler.DeployTimeoutReason))
+		Expect(degraded.Message).To(Equal("Deployment did not complete within 1m0s"))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning DeployTimeout " + degraded.Message))
+
+		doReconcile(args)
+		Expect(drainEvents(args.recorder)).ToNot(ContainElement(HavePrefix("Warning DeployTimeout")))
+		Expect(conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded).LastTransitionTime).
+			To(Equal(degraded.LastTransitionTime))
+
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(conditions.IsStatusConditionFalse(args.config.Status.Conditions, conditions.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should fail deployment after the deadline until its resources are ready", func() {
+		args := startDeploy(reconciler.DeployDeadlineFail)
+		drainEvents(args.recorder)
+		expireDeploy(args)
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		degraded := conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal(reconciler.DeployTimeoutReason))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning DeployTimeout " + degraded.Message))
+
+		// the deployment isn't restarted with a new deadline
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		Expect(conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded).Reason).
+			To(Equal(reconciler.DeployTimeoutReason))
+
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(args.config.Status.ObservedVersion).To(Equal(version))
+		Expect(conditions.IsStatusConditionTrue(args.config.Status.Conditions, conditions.ConditionAvailable)).To(BeTrue())
+		Expect(conditions.IsStatusConditionFalse(args.config.Status.Conditions, conditions.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should refuse invalid deadlines", func() {
+		args := createArgs(version)
+		Expect(func() { args.reconciler.WithDeployDeadline(0, reconciler.DeployDeadlineFail) }).To(Panic())
+		Expect(func() { args.reconciler.WithDeployDeadline(time.Minute, "Rollback") }).To(Panic())
+	})
+})
@@ -0,0 +1,109 @@
+package reconciler
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// desiredResourcesKey is the context key of the desired resources of the reconciled CR
+type desiredResourcesKey struct{}
+
+// desiredResources computes the desired resources of a CR once per reconcile pass
+type desiredResources struct {
+	lock     sync.Mutex
+	computed bool
+	waves    [][]client.Object
+	err      error
+	compute  func() ([][]client.Object, error)
+}
+
+func (d *desiredResources) get() ([][]client.Object, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.computed {
+		d.waves, d.err = d.compute()
+		d.computed = true
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	// callers modify the objects, e.g. when preparing them for create
+	waves := make([][]client.Object, len(d.waves))
+	for i, wave := range d.waves {
+		waves[i] = copyObjects(wave)
+	}
+	return waves, nil
+}
+
+// withDesiredResources returns a context sharing the desired resources of the cr across the phases of a reconcile pass
+func (r *Reconciler) withDesiredResources(ctx context.Context, cr client.Object) context.Context {
+	return context.WithValue(ctx, desiredResourcesKey{}, &desiredResources{
+		compute: func() ([][]client.Object, error) {
+			return r.computeDesiredWaves(ctx, cr)
+		},
+	})
+}
+
+// DesiredResources returns copies of the desired resources of the CR reconciled with ctx, computed once per reconcile
+// pass of Reconciler.Reconcile. Hooks and callbacks receiving the context see the same resources the Reconciler
+// applies. The second return value is false if ctx isn't the context of a reconcile pass.
+func DesiredResources(ctx context.Context) ([]client.Object, bool, error) {
+	desired, ok := ctx.Value(desiredResourcesKey{}).(*desiredResources)
+	if !ok {
+		return nil, false, nil
+	}
+	waves, err := desired.get()
+	if err != nil {
+		return nil, true, err
+	}
+	return flatten(waves), true, nil
+}
+
+// desiredWaves returns the desired resources of the cr, grouped into the waves declared by the CrManager if it
+// implements ApplyWavesCrManager, or as a single group otherwise
+func (r *Reconciler) desiredWaves(ctx context.Context, cr client.Object) ([][]client.Object, error) {
+	if desired, ok := ctx.Value(desiredResourcesKey{}).(*desiredResources); ok {
+		return desired.get()
+	}
+	return r.computeDesiredWaves(ctx, cr)
+}
+
+func (r *Reconciler) computeDesiredWaves(ctx context.Context, cr client.Object) ([][]client.Object, error) {
+	if m, ok := r.crManager.(ApplyWavesCrManager); ok {
+		return m.GetResourceWaves(ctx, cr)
+	}
+
+	var resources []client.Object
+	var err error
+	if m, ok := r.crManager.(ContextCrManager); ok {
+		resources, err = m.GetAllResourcesContext(ctx, cr)
+	} else {
+		resources, err = r.crManager.GetAllResources(cr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return [][]client.Object{resources}, nil
+}
+
+func copyObjects(objs []client.Object) []client.Object {
+	if objs == nil {
+		return nil
+	}
+	result := make([]client.Object, len(objs))
+	for i, obj := range objs {
+		result[i] = obj.DeepCopyObject().(client.Object)
+	}
+	return result
+}
+
+func flatten(waves [][]client.Object) []client.Object {
+	var resources []client.Object
+	for _, wave := range waves {
+		resources = append(resources, wave...)
+	}
+	return resources
+}
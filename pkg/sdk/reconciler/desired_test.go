@@ -0,0 +1,86 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Desired resources", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	It("should compute desired resources once per reconcile pass", func() {
+		args := createArgs(version)
+		crManager := &countingCrManager{}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+
+		doReconcile(args)
+		Expect(crManager.calls).To(Equal(1))
+
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+
+		crManager.calls = 0
+		args.version = "v1.6.0"
+		doReconcile(args)
+		Expect(crManager.calls).To(Equal(1))
+	})
+
+	It("should expose desired resources to hooks and callbacks", func() {
+		args := createArgs(version)
+		var seen []string
+		receivedBy := func(ctx context.Context, receiver string) {
+			resources, ok, err := reconciler.DesiredResources(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			if ok {
+				Expect(resources).To(HaveLen(1))
+				Expect(resources[0].GetName()).To(Equal(testcr.OperatorDeploymentName))
+				seen = append(seen, receiver)
+			}
+		}
+		getCache := func() cache.Cache {
+			return nil
+		}
+		args.reconciler = reconciler.NewReconciler(&contextCrManager{receivedBy: func(context.Context, string) {}}, log, args.client, &contextCallbackDispatcher{receivedBy: receivedBy}, args.client.Scheme(), getCache, createVersionLabel, "update-version", "last-applied-config", 0, finalizerName, true, args.recorder).
+			WithController(args.mockController).
+			WithContextPerishablesSynchronizer(func(ctx context.Context, _ client.Object, _ logr.Logger) error {
+				receivedBy(ctx, "PerishablesSynchronizer")
+				return nil
+			})
+
+		doReconcile(args)
+
+		Expect(seen).To(ContainElements("PerishablesSynchronizer", string(callbacks.ReconcileStatePreCreate), string(callbacks.ReconcileStatePostCreate)))
+	})
+
+	It("should not expose desired resources outside of reconcile pass", func() {
+		_, ok, err := reconciler.DesiredResources(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+})
+
+type countingCrManager struct {
+	testcr.ConfigCrManager
+	calls int
+}
+
+func (m *countingCrManager) GetAllResources(cr client.Object) ([]client.Object, error) {
+	m.calls++
+	return m.ConfigCrManager.GetAllResources(cr)
+}
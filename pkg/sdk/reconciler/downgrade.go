@@ -0,0 +1,50 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	downgradeStarted    = "DowngradeStarted"
+	downgradeRefused    = "DowngradeRefused"
+	downgradeHookFailed = "DowngradeHookFailed"
+)
+
+// DowngradeHook is expected to prepare the resources deployed by fromVersion to be reconciled by the older toVersion
+type DowngradeHook func(ctx context.Context, cr client.Object, fromVersion, toVersion string) error
+
+// runDowngradeHooks executes the downgrade hooks, stopping on the first failure
+func (r *Reconciler) runDowngradeHooks(ctx context.Context, cr client.Object, fromVersion, toVersion string) error {
+	for _, hook := range r.downgradeHooks {
+		if err := hook(ctx, cr, fromVersion, toVersion); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, downgradeHookFailed, fmt.Sprintf("Downgrade from version %s to %s failed, %v", fromVersion, toVersion, err))
+			return err
+		}
+	}
+	return nil
+}
+
+// markDowngradeRefused reports the refused downgrade in the Degraded condition, returns whether it changed
+func (r *Reconciler) markDowngradeRefused(ctx context.Context, cr client.Object, err *DowngradeError) bool {
+	message := fmt.Sprintf("Refused downgrade from version %s to %s, downgrade policy %s", err.CurrentVersion, err.TargetVersion, err.Policy)
+
+	status := r.status(ctx, cr)
+	degraded := conditions.FindStatusCondition(status.Conditions, conditions.ConditionDegraded)
+	if degraded != nil && degraded.Status == corev1.ConditionTrue && degraded.Reason == downgradeRefused && degraded.Message == message {
+		return false
+	}
+
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    conditions.ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  downgradeRefused,
+		Message: message,
+	})
+	r.recorder.Event(cr, corev1.EventTypeWarning, downgradeRefused, message)
+	return true
+}
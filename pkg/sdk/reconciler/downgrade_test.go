@@ -0,0 +1,95 @@
+package reconciler_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+)
+
+var _ = Describe("Downgrading operator", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// deploy deploys prevVersion and switches to the newVersion operator
+	deploy := func(prevVersion, newVersion string, policy reconciler.DowngradePolicy) *args {
+		args := createArgs(prevVersion)
+		args.reconciler.WithDowngradePolicy(policy)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		setDeploymentsDegraded(args)
+		drainEvents(args.recorder)
+
+		args.version = newVersion
+		return args
+	}
+
+	expectDowngraded := func(args *args, newVersion string) {
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseUpgrading))
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(args.config.Status.ObservedVersion).To(Equal(newVersion))
+	}
+
+	It("should refuse and report downgrade", func() {
+		args := deploy("v1.10.0", "v1.9.5", reconciler.DowngradeRefuse)
+
+		doReconcileError(args)
+
+		degraded := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal("DowngradeRefused"))
+		Expect(degraded.Message).To(Equal("Refused downgrade from version v1.10.0 to v1.9.5, downgrade policy refuse"))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning DowngradeRefused " + degraded.Message))
+		Expect(args.config.Status.ObservedVersion).To(Equal("v1.10.0"))
+	})
+
+	It("should downgrade within patch version", func() {
+		args := deploy("v1.10.1", "v1.10.0", reconciler.DowngradeAllowWithinPatch)
+
+		doReconcile(args)
+
+		Expect(drainEvents(args.recorder)).To(ContainElement("Normal DowngradeStarted Started downgrade from version v1.10.1 to v1.10.0, downgrade policy allow-within-patch"))
+		expectDowngraded(args, "v1.10.0")
+	})
+
+	It("should refuse downgrade across minor versions within patch version", func() {
+		args := deploy("v1.10.0", "v1.9.5", reconciler.DowngradeAllowWithinPatch)
+
+		doReconcileError(args)
+
+		degraded := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionDegraded)
+		Expect(degraded.Message).To(Equal("Refused downgrade from version v1.10.0 to v1.9.5, downgrade policy allow-within-patch"))
+	})
+
+	It("should run downgrade hooks before downgrading", func() {
+		args := deploy("v1.10.0", "v1.9.5", reconciler.DowngradeAllowWithDowngradePath)
+		var hookErr error
+		var calls []string
+		args.reconciler.WithDowngradeHook(func(_ context.Context, _ client.Object, fromVersion, toVersion string) error {
+			calls = append(calls, fromVersion+"->"+toVersion)
+			return hookErr
+		})
+
+		hookErr = fmt.Errorf("not yet")
+		doReconcileError(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning DowngradeHookFailed Downgrade from version v1.10.0 to v1.9.5 failed, not yet"))
+
+		hookErr = nil
+		doReconcile(args)
+		Expect(calls).To(Equal([]string{"v1.10.0->v1.9.5", "v1.10.0->v1.9.5"}))
+		expectDowngraded(args, "v1.9.5")
+		Expect(calls).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,304 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+const (
+	reconcileFailed    = "ReconcileFailed"
+	reconcileSucceeded = "ReconcileSucceeded"
+	errorResolved      = "ErrorResolved"
+
+	// reportedResourceErrors is the number of failed resources named in the CR conditions
+	reportedResourceErrors = 3
+)
+
+// TerminalError is returned by hooks and callbacks when the reconciliation can't succeed until its cause is fixed,
+// e.g. an invalid configuration. The CR is moved to the Error phase with Reason in its Degraded condition and the
+// request isn't requeued. The next reconciliation succeeding moves the CR back to the Deploying phase.
+type TerminalError struct {
+	Reason string
+	Err    error
+}
+
+func (e *TerminalError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TerminalError) Unwrap() error {
+	return e.Err
+}
+
+// RetryableError is returned by hooks and callbacks when the reconciliation is expected to succeed later. The request
+// is requeued after RequeueAfter, or with the exponential backoff of the controller if it is zero, as any other
+// error.
+type RetryableError struct {
+	RequeueAfter time.Duration
+	Err          error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// ResourceOperation is the operation on a managed resource that failed
+type ResourceOperation string
+
+const (
+	// ResourceOperationCreate is the creation of a missing resource
+	ResourceOperationCreate ResourceOperation = "create"
+	// ResourceOperationUpdate is the update of an existing resource to its desired state
+	ResourceOperationUpdate ResourceOperation = "update"
+	// ResourceOperationDelete is the deletion of a resource recreated because of changed immutable fields
+	ResourceOperationDelete ResourceOperation = "delete"
+)
+
+// ResourceError is the failure of an operation on a single managed resource. Such failures don't stop the
+// reconciliation of the remaining resources.
+type ResourceError struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Operation        ResourceOperation
+	Err              error
+}
+
+func (e *ResourceError) Error() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + e.Name
+	}
+	return fmt.Sprintf("failed to %s %s %s: %v", e.Operation, e.GroupVersionKind.GroupKind(), name, e.Err)
+}
+
+func (e *ResourceError) Unwrap() error {
+	return e.Err
+}
+
+// ResourceErrors is returned by ReconcileUpdate when some managed resources failed to be created or updated. Both
+// ResourceErrors and the individual ResourceError values can be extracted with errors.As.
+type ResourceErrors struct {
+	Errors []*ResourceError
+}
+
+func (e *ResourceErrors) Error() string {
+	return fmt.Sprintf("reconcile encountered %d errors: %s", len(e.Errors), e.Summary(len(e.Errors)))
+}
+
+func (e *ResourceErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Summary describes the first max failures, followed by the number of the other ones
+func (e *ResourceErrors) Summary(max int) string {
+	var messages []string
+	for i, err := range e.Errors {
+		if i == max {
+			messages = append(messages, fmt.Sprintf("and %d more", len(e.Errors)-max))
+			break
+		}
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// resourceError reports the failed operation on obj
+func (r *Reconciler) resourceError(obj client.Object, operation ResourceOperation, err error) *ResourceError {
+	return &ResourceError{
+		GroupVersionKind: r.groupVersionKind(obj),
+		Namespace:        obj.GetNamespace(),
+		Name:             obj.GetName(),
+		Operation:        operation,
+		Err:              err,
+	}
+}
+
+// groupVersionKind returns the GVK of obj known to the scheme, the one set in obj otherwise
+func (r *Reconciler) groupVersionKind(obj client.Object) schema.GroupVersionKind {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return obj.GetObjectKind().GroupVersionKind()
+	}
+	return gvk
+}
+
+// resourceErrors aggregates the failures returned by reconcileResources
+func resourceErrors(errs []error) *ResourceErrors {
+	result := &ResourceErrors{}
+	for _, err := range errs {
+		var resourceErr *ResourceError
+		if errors.As(err, &resourceErr) {
+			result.Errors = append(result.Errors, resourceErr)
+		}
+	}
+	return result
+}
+
+// markReconcileFailed reports the failed resources in the CR status: the Degraded condition of a deployed CR, the
+// Progressing one of a CR being deployed or upgraded
+func (r *Reconciler) markReconcileFailed(ctx context.Context, cr client.Object, errs *ResourceErrors) error {
+	status := r.status(ctx, cr)
+	conditionType := conditions.ConditionProgressing
+	if status.Phase == sdkapi.PhaseDeployed {
+		conditionType = conditions.ConditionDegraded
+	}
+	message := fmt.Sprintf("Failed to reconcile %d resources: %s", len(errs.Errors), errs.Summary(reportedResourceErrors))
+
+	condition := conditions.FindStatusCondition(status.Conditions, conditionType)
+	if condition != nil && condition.Status == corev1.ConditionTrue && condition.Reason == reconcileFailed && condition.Message == message {
+		return nil
+	}
+
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    conditionType,
+		Status:  corev1.ConditionTrue,
+		Reason:  reconcileFailed,
+		Message: message,
+	})
+	return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
+
+// clearReconcileFailed resets the Progressing condition set by markReconcileFailed, the Degraded one is reset by
+// CheckDegraded
+func (r *Reconciler) clearReconcileFailed(ctx context.Context, cr client.Object) error {
+	status := r.status(ctx, cr)
+	progressing := conditions.FindStatusCondition(status.Conditions, conditions.ConditionProgressing)
+	if progressing == nil || progressing.Reason != reconcileFailed {
+		return nil
+	}
+
+	progressingStatus := corev1.ConditionTrue
+	if status.Phase == sdkapi.PhaseDeployed {
+		progressingStatus = corev1.ConditionFalse
+	}
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    conditions.ConditionProgressing,
+		Status:  progressingStatus,
+		Reason:  reconcileSucceeded,
+		Message: "All resources reconciled",
+	})
+	// the status of the condition may be unchanged, which doesn't make Reconcile update the status
+	return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
+
+// handleReconcileError moves the cr to the Error phase on a TerminalError and requeues the request as asked by a
+// RetryableError
+func (r *Reconciler) handleReconcileError(ctx context.Context, logger logr.Logger, cr client.Object, result reconcile.Result, err error) (reconcile.Result, error) {
+	var terminalErr *TerminalError
+	var retryableErr *RetryableError
+	if err == nil {
+		r.completeRecovery(ctx, cr)
+		return result, nil
+	}
+
+	// the cr stays in the Error phase it was recovering from
+	r.abortRecovery(ctx, cr)
+	switch {
+	case errors.As(err, &terminalErr) && cr.GetDeletionTimestamp() == nil:
+		logger.Error(err, "Reconcile failed, moving to Error state", "reason", terminalErr.Reason)
+		status := r.status(ctx, cr)
+		sdk.MarkCrFailed(cr, status, terminalErr.Reason, err.Error(), r.recorder)
+		if updateErr := r.CrUpdateStatusContext(ctx, sdkapi.PhaseError, cr); updateErr != nil {
+			return reconcile.Result{}, updateErr
+		}
+		return reconcile.Result{}, reconcile.TerminalError(err)
+	case errors.As(err, &retryableErr) && retryableErr.RequeueAfter > 0:
+		logger.Error(err, "Reconcile failed, retrying", "requeueAfter", retryableErr.RequeueAfter)
+		return reconcile.Result{RequeueAfter: retryableErr.RequeueAfter}, nil
+	}
+	return result, err
+}
+
+// recoverableError checks whether the cr is in the Error phase it leaves once the reconciliation succeeds again;
+// a failed upgrade waits for another operator version instead, a failed deployment for its resources to become ready
+func (r *Reconciler) recoverableError(ctx context.Context, cr client.Object, operatorVersion string) bool {
+	return r.status(ctx, cr).Phase == sdkapi.PhaseError && !r.upgradeFailed(ctx, cr, operatorVersion) && !r.deployTimedOut(ctx, cr)
+}
+
+// errorRecovery is the state of a cr recovering from the Error phase
+type errorRecovery struct {
+	// failed is the status of the cr in the Error phase
+	failed *sdkapi.Status
+	// message describes the resolved error
+	message string
+}
+
+// recoverFromError moves the cr from the Error phase back to the Deploying one, from which it reaches the Deployed phase
+// once its resources are ready. A cr deployed by another operator version goes to the Deployed phase instead, so that
+// it is upgraded. Within a reconcile pass the recovery is tentative: a failing pass restores the Error phase and only
+// a succeeding one records the ErrorResolved event.
+func (r *Reconciler) recoverFromError(ctx context.Context, cr client.Object, operatorVersion string) error {
+	status := r.status(ctx, cr)
+	phase := sdkapi.PhaseDeploying
+	if status.ObservedVersion != "" && status.ObservedVersion != operatorVersion {
+		phase = sdkapi.PhaseDeployed
+	}
+	message := "Recovering from error"
+	if degraded := conditions.FindStatusCondition(status.Conditions, conditions.ConditionDegraded); degraded != nil && degraded.Message != "" {
+		message = fmt.Sprintf("Recovering from error: %s", degraded.Message)
+	}
+
+	batch := r.statusBatch(ctx, cr)
+	if batch == nil {
+		sdk.MarkCrDeploying(cr, status, errorResolved, message, r.recorder)
+		return r.CrUpdateStatusContext(ctx, phase, cr)
+	}
+	failed := &sdkapi.Status{}
+	status.DeepCopyInto(failed)
+	batch.recovery = &errorRecovery{failed: failed, message: message}
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:   conditions.ConditionAvailable,
+		Status: corev1.ConditionFalse,
+	})
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    conditions.ConditionProgressing,
+		Status:  corev1.ConditionTrue,
+		Reason:  errorResolved,
+		Message: message,
+	})
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:   conditions.ConditionDegraded,
+		Status: corev1.ConditionFalse,
+	})
+	return r.CrUpdateStatusContext(ctx, phase, cr)
+}
+
+// completeRecovery records the ErrorResolved event of the cr the reconcile pass recovered from the Error phase
+func (r *Reconciler) completeRecovery(ctx context.Context, cr client.Object) {
+	if batch := r.statusBatch(ctx, cr); batch != nil && batch.recovery != nil {
+		r.recorder.Event(cr, corev1.EventTypeNormal, errorResolved, batch.recovery.message)
+		batch.recovery = nil
+	}
+}
+
+// abortRecovery restores the status of the cr in the Error phase the failed reconcile pass tried to recover from
+func (r *Reconciler) abortRecovery(ctx context.Context, cr client.Object) {
+	if batch := r.statusBatch(ctx, cr); batch != nil && batch.recovery != nil {
+		*r.status(ctx, cr) = *batch.recovery.failed
+		batch.recovery = nil
+	}
+}
@@ -0,0 +1,240 @@
+package reconciler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Resource errors", func() {
+	errRefused := fmt.Errorf("refused")
+	var refuse bool
+
+	BeforeEach(func() {
+		refuse = false
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// createRefusingArgs returns args with a client refusing to create and update Deployments while refuse is set
+	createRefusingArgs := func(crManager reconciler.CrManager) *args {
+		args := createArgs(version)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*appsv1.Deployment); ok && refuse {
+					return errRefused
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*appsv1.Deployment); ok && refuse {
+					return errRefused
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		return args
+	}
+
+	reconcileWithError := func(args *args) error {
+		_, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), args.version, log)
+		Expect(err).To(HaveOccurred())
+
+		args.config, _ = getConfig(args.client, args.config)
+		return err
+	}
+
+	It("should identify the resources failed to be created", func() {
+		refuse = true
+		args := createRefusingArgs(&testcr.ConfigCrManager{})
+
+		err := reconcileWithError(args)
+
+		var resourceErrors *reconciler.ResourceErrors
+		Expect(errors.As(err, &resourceErrors)).To(BeTrue())
+		Expect(resourceErrors.Errors).To(HaveLen(1))
+		var resourceError *reconciler.ResourceError
+		Expect(errors.As(err, &resourceError)).To(BeTrue())
+		Expect(resourceError.GroupVersionKind).To(Equal(appsv1.SchemeGroupVersion.WithKind("Deployment")))
+		Expect(resourceError.Namespace).To(Equal(testcr.Namespace))
+		Expect(resourceError.Name).To(Equal(testcr.OperatorDeploymentName))
+		Expect(resourceError.Operation).To(Equal(reconciler.ResourceOperationCreate))
+		Expect(errors.Is(err, errRefused)).To(BeTrue())
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		progressing := conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionProgressing)
+		Expect(progressing).ToNot(BeNil())
+		Expect(progressing.Status).To(Equal(corev1.ConditionTrue))
+		Expect(progressing.Reason).To(Equal("ReconcileFailed"))
+		Expect(progressing.Message).To(ContainSubstring("failed to create Deployment.apps " + testcr.Namespace + "/" + testcr.OperatorDeploymentName + ": refused"))
+
+		refuse = false
+		doReconcile(args)
+		progressing = conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionProgressing)
+		Expect(progressing.Reason).ToNot(Equal("ReconcileFailed"))
+	})
+
+	It("should report the resources failed to be updated in the Degraded condition", func() {
+		crManager := &mutatingCrManager{mutate: func(*appsv1.Deployment) {}}
+		args := createRefusingArgs(crManager)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+
+		refuse = true
+		crManager.mutate = func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}
+		err := reconcileWithError(args)
+
+		var resourceError *reconciler.ResourceError
+		Expect(errors.As(err, &resourceError)).To(BeTrue())
+		Expect(resourceError.Operation).To(Equal(reconciler.ResourceOperationUpdate))
+		degraded := conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded)
+		Expect(degraded).ToNot(BeNil())
+		Expect(degraded.Status).To(Equal(corev1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal("ReconcileFailed"))
+		Expect(degraded.Message).To(Equal("Failed to reconcile 1 resources: failed to update Deployment.apps " + testcr.Namespace + "/" + testcr.OperatorDeploymentName + ": refused"))
+
+		refuse = false
+		doReconcile(args)
+		Expect(conditions.IsStatusConditionFalse(args.config.Status.Conditions, conditions.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should summarise the first failures", func() {
+		errs := &reconciler.ResourceErrors{}
+		for i := 0; i < 4; i++ {
+			errs.Errors = append(errs.Errors, &reconciler.ResourceError{
+				GroupVersionKind: corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+				Namespace:        testcr.Namespace,
+				Name:             fmt.Sprintf("config-%d", i),
+				Operation:        reconciler.ResourceOperationUpdate,
+				Err:              errRefused,
+			})
+		}
+
+		Expect(errs.Summary(2)).To(Equal("failed to update ConfigMap " + testcr.Namespace + "/config-0: refused; " +
+			"failed to update ConfigMap " + testcr.Namespace + "/config-1: refused; and 2 more"))
+	})
+})
+
+var _ = Describe("Terminal and retryable errors", func() {
+	var callbackErr error
+
+	BeforeEach(func() {
+		callbackErr = nil
+		invokeCallbacks = func(_ interface{}, s callbacks.ReconcileState, _ client.Object, _ client.Object) error {
+			if s == callbacks.ReconcileStatePreUpdate || s == callbacks.ReconcileStatePreCreate {
+				return callbackErr
+			}
+			return nil
+		}
+	})
+
+	// deploy deploys the CR and changes its desired deployment, so that the next reconciliation updates it
+	deploy := func() *args {
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(*appsv1.Deployment) {}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+
+		crManager.mutate = func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}
+		return args
+	}
+
+	It("should move the CR to Error on terminal error and recover once it's gone", func() {
+		args := deploy()
+
+		callbackErr = &reconciler.TerminalError{Reason: "InvalidConfig", Err: fmt.Errorf("invalid config")}
+		result, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), args.version, log)
+		Expect(err).To(MatchError(reconcile.TerminalError(nil)))
+		Expect(result.Requeue).To(BeFalse())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		args.config, err = getConfig(args.client, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		degraded := conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal("InvalidConfig"))
+		Expect(degraded.Message).To(ContainSubstring("invalid config"))
+
+		callbackErr = nil
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(conditions.IsStatusConditionTrue(args.config.Status.Conditions, conditions.ConditionAvailable)).To(BeTrue())
+		Expect(conditions.IsStatusConditionFalse(args.config.Status.Conditions, conditions.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should stay in Error while the terminal error persists", func() {
+		args := deploy()
+
+		callbackErr = &reconciler.TerminalError{Reason: "InvalidConfig", Err: fmt.Errorf("invalid config")}
+		doReconcileError(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		failed := args.config.DeepCopy()
+		drainEvents(args.recorder)
+
+		for i := 0; i < 2; i++ {
+			doReconcileError(args)
+			Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+			Expect(args.config.Status.PhaseTransitionTime).To(Equal(failed.Status.PhaseTransitionTime))
+			degraded := conditions.FindStatusCondition(args.config.Status.Conditions, conditions.ConditionDegraded)
+			Expect(degraded.LastTransitionTime).To(Equal(conditions.FindStatusCondition(failed.Status.Conditions, conditions.ConditionDegraded).LastTransitionTime))
+		}
+		Expect(drainEvents(args.recorder)).ToNot(ContainElement(HavePrefix("Normal ErrorResolved")))
+
+		callbackErr = nil
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Normal ErrorResolved Recovering from error: invalid config"))
+	})
+
+	It("should requeue retryable error after the requested delay", func() {
+		args := deploy()
+
+		callbackErr = &reconciler.RetryableError{RequeueAfter: time.Minute, Err: fmt.Errorf("not yet")}
+		result, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), args.version, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+		args.config, err = getConfig(args.client, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should deploy the CR again from Error when its resources are missing", func() {
+		args := createArgs(version)
+		args.config.Status.Phase = sdkapi.PhaseError
+		Expect(args.client.Status().Update(context.TODO(), args.config)).To(Succeed())
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+})
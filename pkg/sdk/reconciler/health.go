@@ -0,0 +1,97 @@
+package reconciler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HealthExpressionAnnotation is the name of the annotation (under the annotation prefix) holding a CEL expression
+// that has to evaluate to true for the annotated managed resource to be ready. The live object is available to the
+// expression as self, e.g. self.status.conditions.exists(c, c.type == 'Ready' && c.status == 'True').
+const HealthExpressionAnnotation = "health-expression"
+
+const (
+	invalidHealthExpression = "InvalidHealthExpression"
+
+	// healthExpressionCostLimit bounds the cost of evaluating a health expression, which may come from user-editable
+	// annotations; an expression exceeding it is not healthy
+	healthExpressionCostLimit = 1000000
+)
+
+// healthExpressions compiles and caches the CEL health expressions
+type healthExpressions struct {
+	env *cel.Env
+
+	lock     sync.Mutex
+	programs map[string]cel.Program
+}
+
+func newHealthExpressions() *healthExpressions {
+	env, err := cel.NewEnv(cel.Variable("self", cel.DynType))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create CEL environment: %v", err))
+	}
+	return &healthExpressions{env: env, programs: map[string]cel.Program{}}
+}
+
+// compile returns the program of the expression, which must evaluate to bool
+func (h *healthExpressions) compile(expression string) (cel.Program, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if program, ok := h.programs[expression]; ok {
+		return program, nil
+	}
+
+	ast, issues := h.env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("invalid health expression %q: %v", expression, issues.Err())
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("health expression %q must evaluate to bool, not %v", expression, ast.OutputType())
+	}
+	program, err := h.env.Program(ast, cel.CostLimit(healthExpressionCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid health expression %q: %v", expression, err)
+	}
+
+	h.programs[expression] = program
+	return program, nil
+}
+
+// evaluate evaluates the expression against obj. A failing evaluation, i.e. because of a status field not present
+// yet or of the cost limit, is reported as not healthy; an error is returned for invalid expressions only.
+func (h *healthExpressions) evaluate(expression string, obj client.Object) (bool, error) {
+	program, err := h.compile(expression)
+	if err != nil {
+		return false, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false, err
+	}
+
+	out, _, err := program.Eval(map[string]interface{}{"self": content})
+	if err != nil {
+		return false, nil
+	}
+	healthy, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("health expression %q evaluated to %v instead of bool", expression, out.Value())
+	}
+	return healthy, nil
+}
+
+// healthExpression returns the health expression of the desired obj: the annotated one or the one set for its GVK
+func (r *Reconciler) healthExpression(obj client.Object, gvk schema.GroupVersionKind) string {
+	if expression, ok := obj.GetAnnotations()[r.annotation(HealthExpressionAnnotation)]; ok {
+		return expression
+	}
+	return r.healthExpressionsByGVK[gvk]
+}
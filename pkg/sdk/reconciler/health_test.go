@@ -0,0 +1,96 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+)
+
+var _ = Describe("Health expressions", func() {
+	healthExpression := reconciler.DefaultAnnotationPrefix + "/" + reconciler.HealthExpressionAnnotation
+	configMapGVK := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	createHealthArgs := func(configMap *corev1.ConfigMap) *args {
+		args := createArgs(version)
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{configMap}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		return args
+	}
+
+	It("should evaluate annotated expression against the live object", func() {
+		configMap := createWaveConfigMap(map[string]string{healthExpression: "self.metadata.labels['ready'] == 'true'"})
+		args := createHealthArgs(configMap)
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+
+		storedObj, err := getObject(args.client, configMap)
+		Expect(err).ToNot(HaveOccurred())
+		storedObj.GetLabels()["ready"] = "true"
+		Expect(args.client.Update(context.TODO(), storedObj)).To(Succeed())
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should evaluate expression set for the GVK", func() {
+		args := createHealthArgs(createWaveConfigMap(nil))
+		args.reconciler.WithHealthExpression(configMapGVK, "self.data.key == 'other'")
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+
+		args.reconciler.WithHealthExpression(configMapGVK, "self.data.key == 'value'")
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should prefer annotated expression", func() {
+		args := createHealthArgs(createWaveConfigMap(map[string]string{healthExpression: "true"}))
+		args.reconciler.WithHealthExpression(configMapGVK, "false")
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should report resource with invalid annotated expression as not ready", func() {
+		args := createHealthArgs(createWaveConfigMap(map[string]string{healthExpression: "self.data.key"}))
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		Expect(drainEvents(args.recorder)).To(ContainElement(HavePrefix("Warning InvalidHealthExpression")))
+	})
+
+	It("should report expression exceeding the cost limit as not healthy", func() {
+		digits := "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]"
+		expression := "true"
+		for _, v := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+			expression = digits + ".all(" + v + ", " + expression + ")"
+		}
+		args := createHealthArgs(createWaveConfigMap(map[string]string{healthExpression: expression}))
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+	})
+
+	It("should reject invalid expression", func() {
+		args := createHealthArgs(createWaveConfigMap(nil))
+		Expect(func() { args.reconciler.WithHealthExpression(configMapGVK, "self.data.") }).To(Panic())
+		Expect(func() { args.reconciler.WithHealthExpression(configMapGVK, "1 + 1") }).To(Panic())
+	})
+})
@@ -0,0 +1,321 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// LastAppliedStorage decides where the last applied configuration of the managed resources is kept
+type LastAppliedStorage string
+
+const (
+	// LastAppliedAnnotation keeps the JSON of the configuration in the last applied configuration annotation, the
+	// default
+	LastAppliedAnnotation LastAppliedStorage = "annotation"
+	// LastAppliedCompressedAnnotation keeps the gzipped and base64 encoded configuration in the annotation, refusing
+	// configurations exceeding sdk.DefaultMaxLastAppliedSize
+	LastAppliedCompressedAnnotation LastAppliedStorage = "compressed-annotation"
+	// LastAppliedHashSnapshot keeps the hash of the configuration in the annotation and the compressed configuration
+	// in the <cr name>-lifecycle-last-applied Secret, in the namespace of the cr or the one set by
+	// WithSnapshotNamespace
+	LastAppliedHashSnapshot LastAppliedStorage = "hash-snapshot"
+)
+
+const (
+	// lastAppliedSnapshotEntries is the number of configurations kept per resource, the previous one is needed while
+	// the update to the latest one hasn't been recorded yet
+	lastAppliedSnapshotEntries = 2
+	// maxLastAppliedSnapshotSize is the size of the data of the snapshot Secret the store refuses to exceed, leaving
+	// room below the size limit of a Secret
+	maxLastAppliedSnapshotSize = 768 * 1024
+)
+
+// lastAppliedEntry is a configuration of a resource kept in the last applied snapshot
+type lastAppliedEntry struct {
+	Hash   string `json:"hash"`
+	Config string `json:"config"`
+}
+
+// lastAppliedStore returns the store of the last applied configurations of the resources managed by the cr
+func (r *Reconciler) lastAppliedStore(cr client.Object) sdk.LastAppliedStore {
+	switch r.lastAppliedStorage {
+	case LastAppliedCompressedAnnotation:
+		return sdk.NewCompressedAnnotationStore(r.lastAppliedConfigAnnotation, sdk.DefaultMaxLastAppliedSize)
+	case LastAppliedHashSnapshot:
+		return &snapshotLastAppliedStore{r: r, cr: cr}
+	}
+	return sdk.NewAnnotationStore(r.lastAppliedConfigAnnotation)
+}
+
+// snapshotLastAppliedStore keeps the configurations in a Secret owned by the cr, so that the managed objects only
+// carry their hash. Saved configurations are pending until commitLastApplied records them once their object was
+// written. The hashes recorded are remembered, so that the Secret is only read and written for the resources whose
+// configuration changed.
+type snapshotLastAppliedStore struct {
+	r  *Reconciler
+	cr client.Object
+}
+
+func (s *snapshotLastAppliedStore) Save(ctx context.Context, obj client.Object) error {
+	config, err := sdk.LastAppliedConfiguration(obj)
+	if err != nil {
+		return err
+	}
+	hash := fmt.Sprintf("%s%x", sdk.LastAppliedHashPrefix, sha256.Sum256(config))
+	sdk.SetAnnotation(s.r.lastAppliedConfigAnnotation, hash, obj)
+	if isPlanning(ctx) {
+		return nil
+	}
+
+	dataKey, err := s.dataKey(obj)
+	if err != nil {
+		return err
+	}
+	compressed, err := sdk.CompressLastAppliedConfiguration(config)
+	if err != nil {
+		return err
+	}
+	s.r.pendingLastApplied.Store(s.pendingKey(dataKey), lastAppliedEntry{Hash: hash, Config: compressed})
+	return nil
+}
+
+// commit records the configuration of obj saved last, if any
+func (s *snapshotLastAppliedStore) commit(ctx context.Context, obj client.Object) error {
+	dataKey, err := s.dataKey(obj)
+	if err != nil {
+		return err
+	}
+	pending, ok := s.r.pendingLastApplied.LoadAndDelete(s.pendingKey(dataKey))
+	if !ok {
+		return nil
+	}
+	entry := pending.(lastAppliedEntry)
+	if committed, ok := s.r.committedLastApplied.Load(s.pendingKey(dataKey)); ok && committed == entry.Hash {
+		// recorded by a previous pass, the Secret isn't read for the unchanged resources
+		return nil
+	}
+
+	err = s.update(ctx, func(secret *corev1.Secret) (bool, error) {
+		entries, err := decodeLastAppliedEntries(secret.Data[dataKey])
+		if err != nil {
+			return false, err
+		}
+		if len(entries) > 0 && entries[0].Hash == entry.Hash {
+			return false, nil
+		}
+
+		entries = append([]lastAppliedEntry{entry}, entries...)
+		if len(entries) > lastAppliedSnapshotEntries {
+			entries = entries[:lastAppliedSnapshotEntries]
+		}
+		bytes, err := json.Marshal(entries)
+		if err != nil {
+			return false, err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[dataKey] = bytes
+
+		size := 0
+		for _, data := range secret.Data {
+			size += len(data)
+		}
+		if size > maxLastAppliedSnapshotSize {
+			return false, fmt.Errorf("last applied configurations in Secret %s/%s would have %d bytes, more than %d allowed; use the %s or %s storage for large configurations",
+				secret.Namespace, secret.Name, size, maxLastAppliedSnapshotSize, LastAppliedAnnotation, LastAppliedCompressedAnnotation)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.r.committedLastApplied.Store(s.pendingKey(dataKey), entry.Hash)
+	return nil
+}
+
+// prune removes the configurations of the resources other than the desired ones
+func (s *snapshotLastAppliedStore) prune(ctx context.Context, desiredObjs []client.Object) error {
+	desired := map[string]bool{}
+	for _, obj := range desiredObjs {
+		dataKey, err := s.dataKey(obj)
+		if err != nil {
+			return err
+		}
+		desired[dataKey] = true
+	}
+	prefix := s.pendingKey("")
+	s.r.committedLastApplied.Range(func(key, _ interface{}) bool {
+		if dataKey := strings.TrimPrefix(key.(string), prefix); dataKey != key.(string) && !desired[dataKey] {
+			s.r.committedLastApplied.Delete(key)
+		}
+		return true
+	})
+
+	return s.update(ctx, func(secret *corev1.Secret) (bool, error) {
+		if secret.ResourceVersion == "" {
+			// no configuration recorded yet
+			return false, nil
+		}
+		pruned := false
+		for dataKey := range secret.Data {
+			if !desired[dataKey] {
+				delete(secret.Data, dataKey)
+				pruned = true
+			}
+		}
+		return pruned, nil
+	})
+}
+
+// pendingKey returns the key of the pending configuration of the resource with the dataKey
+func (s *snapshotLastAppliedStore) pendingKey(dataKey string) string {
+	return string(s.cr.GetUID()) + "/" + dataKey
+}
+
+func (s *snapshotLastAppliedStore) Load(ctx context.Context, obj client.Object) ([]byte, error) {
+	value, ok := obj.GetAnnotations()[s.r.lastAppliedConfigAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	if !strings.HasPrefix(value, sdk.LastAppliedHashPrefix) {
+		// recorded before switching to the snapshot
+		return sdk.DecodeLastAppliedConfiguration(value)
+	}
+
+	dataKey, err := s.dataKey(obj)
+	if err != nil {
+		return nil, err
+	}
+	if pending, ok := s.r.pendingLastApplied.Load(s.pendingKey(dataKey)); ok && pending.(lastAppliedEntry).Hash == value {
+		// unchanged since the last update
+		return sdk.DecodeLastAppliedConfiguration(pending.(lastAppliedEntry).Config)
+	}
+	key, err := s.r.crSnapshotKey(s.cr, "lifecycle-last-applied")
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{}
+	if err = s.r.client.Get(ctx, key, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries, err := decodeLastAppliedEntries(secret.Data[dataKey])
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Hash == value {
+			return sdk.DecodeLastAppliedConfiguration(entry.Config)
+		}
+	}
+	return nil, nil
+}
+
+// update modifies the snapshot Secret, creating it if missing
+func (s *snapshotLastAppliedStore) update(ctx context.Context, modify func(*corev1.Secret) (bool, error)) error {
+	key, err := s.r.crSnapshotKey(s.cr, "lifecycle-last-applied")
+	if err != nil {
+		return err
+	}
+
+	// resources may be reconciled concurrently
+	s.r.lastAppliedLock.Lock()
+	defer s.r.lastAppliedLock.Unlock()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret := &corev1.Secret{}
+		exists := true
+		if err := s.r.client.Get(ctx, key, secret); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			exists = false
+			secret.Name = key.Name
+			secret.Namespace = key.Namespace
+			// not labeled with the create version label, so that it isn't considered unused
+			if err = controllerutil.SetOwnerReference(s.cr, secret, s.r.scheme); err != nil {
+				return err
+			}
+		}
+
+		modified, err := modify(secret)
+		if err != nil || !modified {
+			return err
+		}
+		if exists {
+			return s.r.client.Update(ctx, secret)
+		}
+		return s.r.client.Create(ctx, secret)
+	})
+}
+
+// dataKey returns the key of the configurations of obj in the snapshot Secret
+func (s *snapshotLastAppliedStore) dataKey(obj client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(obj, s.r.scheme)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(gvk.Kind) + "." + gvk.Group + "." + obj.GetNamespace() + "." + obj.GetName(), nil
+}
+
+// commitLastApplied records the last applied configuration of obj saved for its create or update, which succeeded
+func (r *Reconciler) commitLastApplied(ctx context.Context, cr client.Object, obj client.Object) error {
+	if store, ok := r.lastAppliedStore(cr).(*snapshotLastAppliedStore); ok && !r.useServerSideApply() {
+		return store.commit(ctx, obj)
+	}
+	return nil
+}
+
+// pruneLastApplied removes the last applied configurations of the resources the cr doesn't manage anymore
+func (r *Reconciler) pruneLastApplied(ctx context.Context, cr client.Object) error {
+	store, ok := r.lastAppliedStore(cr).(*snapshotLastAppliedStore)
+	if !ok {
+		return nil
+	}
+	desiredObjs, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return err
+	}
+	return store.prune(ctx, desiredObjs)
+}
+
+// dropPendingLastApplied forgets the configurations of the resources of the cr never committed, and the hashes of the
+// ones committed
+func (r *Reconciler) dropPendingLastApplied(cr client.Object) {
+	prefix := string(cr.GetUID()) + "/"
+	for _, configs := range []*sync.Map{&r.pendingLastApplied, &r.committedLastApplied} {
+		configs.Range(func(key, _ interface{}) bool {
+			if strings.HasPrefix(key.(string), prefix) {
+				configs.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+func decodeLastAppliedEntries(data []byte) ([]lastAppliedEntry, error) {
+	var entries []lastAppliedEntry
+	if len(data) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
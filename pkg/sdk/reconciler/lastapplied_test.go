@@ -0,0 +1,281 @@
+package reconciler_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Last applied storages", func() {
+	operatorDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// deployWithoutMinReadySeconds deploys the deployment with minReadySeconds, then stops setting it. The field is
+	// removed only if the last applied configuration is found.
+	deployWithoutMinReadySeconds := func(storage reconciler.LastAppliedStorage) (*args, *appsv1.Deployment) {
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithSnapshotNamespace(testcr.Namespace).
+			WithLastAppliedStorage(storage)
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.MinReadySeconds).To(BeEquivalentTo(5))
+
+		crManager.mutate = func(*appsv1.Deployment) {}
+		doReconcile(args)
+
+		deployment, err = getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.MinReadySeconds).To(BeZero())
+		return args, deployment
+	}
+
+	It("should keep compressed configuration in annotation", func() {
+		_, deployment := deployWithoutMinReadySeconds(reconciler.LastAppliedCompressedAnnotation)
+
+		Expect(deployment.Annotations["last-applied-config"]).To(HavePrefix("gzip+base64:"))
+	})
+
+	It("should keep configuration hash in annotation and configuration in snapshot", func() {
+		args, deployment := deployWithoutMinReadySeconds(reconciler.LastAppliedHashSnapshot)
+
+		Expect(deployment.Annotations["last-applied-config"]).To(HavePrefix("sha256:"))
+		secret := &corev1.Secret{}
+		Expect(args.client.Get(context.TODO(), client.ObjectKey{Namespace: testcr.Namespace, Name: args.config.Name + "-lifecycle-last-applied"}, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKey("deployment.apps." + testcr.Namespace + "." + testcr.OperatorDeploymentName))
+		Expect(metav1.IsControlledBy(secret, args.config)).To(BeFalse())
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+	})
+
+	// snapshotEntries returns the configurations of the resource with the data key recorded in the snapshot
+	snapshotEntries := func(args *args, dataKey string) []map[string]string {
+		secret := &corev1.Secret{}
+		Expect(args.client.Get(context.TODO(), client.ObjectKey{Namespace: testcr.Namespace, Name: args.config.Name + "-lifecycle-last-applied"}, secret)).To(Succeed())
+		var entries []map[string]string
+		if data, ok := secret.Data[dataKey]; ok {
+			Expect(json.Unmarshal(data, &entries)).To(Succeed())
+		}
+		return entries
+	}
+	deploymentKey := "deployment.apps." + testcr.Namespace + "." + testcr.OperatorDeploymentName
+
+	It("should record configuration in snapshot only after the update succeeded", func() {
+		refuse := false
+		args := createArgs(version)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*appsv1.Deployment); ok && refuse {
+					return fmt.Errorf("refused")
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		crManager := &mutatingCrManager{mutate: func(*appsv1.Deployment) {}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithSnapshotNamespace(testcr.Namespace).
+			WithLastAppliedStorage(reconciler.LastAppliedHashSnapshot)
+		doReconcile(args)
+		Expect(snapshotEntries(args, deploymentKey)).To(HaveLen(1))
+
+		refuse = true
+		crManager.mutate = func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}
+		doReconcileError(args)
+		Expect(snapshotEntries(args, deploymentKey)).To(HaveLen(1))
+
+		refuse = false
+		doReconcile(args)
+		entries := snapshotEntries(args, deploymentKey)
+		Expect(entries).To(HaveLen(2))
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries[0]["hash"]).To(Equal(deployment.Annotations["last-applied-config"]))
+	})
+
+	It("should not read the snapshot for unchanged resources", func() {
+		snapshotReads := 0
+		args := createArgs(version)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if key.Name == args.config.Name+"-lifecycle-last-applied" {
+					snapshotReads++
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+		crManager := &mutatingCrManager{mutate: func(*appsv1.Deployment) {}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithSnapshotNamespace(testcr.Namespace).
+			WithLastAppliedStorage(reconciler.LastAppliedHashSnapshot)
+		doReconcile(args)
+		Expect(snapshotReads).ToNot(BeZero())
+
+		snapshotReads = 0
+		doReconcile(args)
+		Expect(snapshotReads).To(BeZero())
+
+		crManager.mutate = func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}
+		doReconcile(args)
+		Expect(snapshotReads).ToNot(BeZero())
+		entries := snapshotEntries(args, deploymentKey)
+		Expect(entries).To(HaveLen(2))
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries[0]["hash"]).To(Equal(deployment.Annotations["last-applied-config"]))
+	})
+
+	It("should prune configurations of unused resources from snapshot", func() {
+		extraKey := "deployment.apps." + testcr.Namespace + ".extra"
+		args := createArgs(version)
+		crManager := &extraResourcesCrManager{extra: func() []client.Object {
+			return []client.Object{testcr.ResourceBuilder.CreateOperatorDeployment("extra", testcr.Namespace, "key", "value", "svc-account", 1, corev1.PodSpec{})}
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithSnapshotNamespace(testcr.Namespace).
+			WithLastAppliedStorage(reconciler.LastAppliedHashSnapshot)
+		doReconcile(args)
+		Expect(snapshotEntries(args, extraKey)).To(HaveLen(1))
+
+		crManager.extra = func() []client.Object { return nil }
+		Expect(args.reconciler.CleanupUnusedResourcesContext(context.TODO(), log, args.config)).To(Succeed())
+
+		Expect(snapshotEntries(args, extraKey)).To(BeEmpty())
+		Expect(snapshotEntries(args, deploymentKey)).To(HaveLen(1))
+	})
+
+	It("should refuse configurations exceeding the size of the snapshot", func() {
+		noise := make([]byte, 1024*1024)
+		_, err := rand.Read(noise)
+		Expect(err).ToNot(HaveOccurred())
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(deployment *appsv1.Deployment) {
+			deployment.Annotations = map[string]string{"noise": base64.StdEncoding.EncodeToString(noise)}
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithSnapshotNamespace(testcr.Namespace).
+			WithLastAppliedStorage(reconciler.LastAppliedHashSnapshot)
+
+		_, err = args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), version, log)
+		Expect(err).To(MatchError(ContainSubstring("more than 786432 allowed")))
+	})
+
+	It("should understand configuration recorded in annotation after switching to snapshot", func() {
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		doReconcile(args)
+
+		crManager.mutate = func(*appsv1.Deployment) {}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithSnapshotNamespace(testcr.Namespace).
+			WithLastAppliedStorage(reconciler.LastAppliedHashSnapshot)
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.MinReadySeconds).To(BeZero())
+		Expect(deployment.Annotations["last-applied-config"]).To(HavePrefix("sha256:"))
+	})
+
+	It("should keep updating after switching from snapshot back to annotation", func() {
+		minReadySeconds := int32(5)
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = minReadySeconds
+		}}
+		withStorage := func(storage reconciler.LastAppliedStorage) {
+			args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+				WithController(args.mockController).
+				WithSnapshotNamespace(testcr.Namespace).
+				WithLastAppliedStorage(storage)
+		}
+		withStorage(reconciler.LastAppliedAnnotation)
+		doReconcile(args)
+
+		minReadySeconds = 7
+		withStorage(reconciler.LastAppliedHashSnapshot)
+		doReconcile(args)
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.MinReadySeconds).To(BeEquivalentTo(7))
+		Expect(deployment.Annotations["last-applied-config"]).To(HavePrefix("sha256:"))
+
+		for _, storage := range []reconciler.LastAppliedStorage{reconciler.LastAppliedAnnotation, reconciler.LastAppliedCompressedAnnotation} {
+			minReadySeconds = 9
+			withStorage(reconciler.LastAppliedHashSnapshot)
+			doReconcile(args)
+
+			// the configuration recorded in the snapshot is unknown, the fields removed are kept
+			minReadySeconds = 0
+			withStorage(storage)
+			doReconcile(args)
+			deployment, err = getDeployment(args.client, operatorDeployment)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployment.Spec.MinReadySeconds).To(BeEquivalentTo(9))
+			Expect(deployment.Annotations["last-applied-config"]).ToNot(HavePrefix("sha256:"))
+
+			minReadySeconds = 3
+			doReconcile(args)
+			minReadySeconds = 0
+			doReconcile(args)
+			deployment, err = getDeployment(args.client, operatorDeployment)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployment.Spec.MinReadySeconds).To(BeZero())
+		}
+	})
+})
+
+type mutatingCrManager struct {
+	testcr.ConfigCrManager
+	mutate func(*appsv1.Deployment)
+}
+
+func (m *mutatingCrManager) GetAllResources(cr client.Object) ([]client.Object, error) {
+	resources, err := m.ConfigCrManager.GetAllResources(cr)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		if deployment, ok := resource.(*appsv1.Deployment); ok {
+			m.mutate(deployment)
+		}
+	}
+	return resources, nil
+}
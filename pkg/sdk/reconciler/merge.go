@@ -0,0 +1,83 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// MergeStrategy decides how the desired state of a managed resource is merged into its live state on update. Labels
+// and annotations are merged the same way by all strategies: the ones of the desired object win, others are kept.
+type MergeStrategy string
+
+const (
+	// MergeStrategyThreeWay computes a three-way merge of the desired object, the live object and the last applied
+	// configuration: fields removed from the desired object are removed, fields not managed by the Reconciler are
+	// kept. This is the strategy of the kinds without a registered one.
+	MergeStrategyThreeWay MergeStrategy = "three-way"
+	// MergeStrategyAdditive only adds the fields of the desired object missing in the live one, values set in the
+	// live object are never changed nor removed
+	MergeStrategyAdditive MergeStrategy = "additive"
+	// MergeStrategyReplace replaces the content of the live object with the desired one, dropping any other field
+	MergeStrategyReplace MergeStrategy = "replace"
+	// MergeStrategyMetadataOnly only merges labels and annotations, the content of the live object is left as is
+	MergeStrategyMetadataOnly MergeStrategy = "metadata-only"
+)
+
+// DefaultMergeStrategies returns the merge strategies the Reconciler is created with: the content of ConfigMaps and
+// Secrets is left to the users
+func DefaultMergeStrategies() map[schema.GroupVersionKind]MergeStrategy {
+	return map[schema.GroupVersionKind]MergeStrategy{
+		corev1.SchemeGroupVersion.WithKind("ConfigMap"): MergeStrategyMetadataOnly,
+		corev1.SchemeGroupVersion.WithKind("Secret"):    MergeStrategyMetadataOnly,
+	}
+}
+
+func (s MergeStrategy) valid() bool {
+	switch s {
+	case MergeStrategyThreeWay, MergeStrategyAdditive, MergeStrategyReplace, MergeStrategyMetadataOnly:
+		return true
+	}
+	return false
+}
+
+// mergeStrategy returns the strategy registered for the kind of obj
+func (r *Reconciler) mergeStrategy(obj client.Object) (MergeStrategy, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return "", err
+	}
+	if strategy, ok := r.mergeStrategies[gvk]; ok {
+		return strategy, nil
+	}
+	return MergeStrategyThreeWay, nil
+}
+
+// mergeObject merges desiredObj into currentObj, which already carries the merged labels and annotations
+func (r *Reconciler) mergeObject(ctx context.Context, cr client.Object, desiredObj, currentObj client.Object) (client.Object, error) {
+	strategy, err := r.mergeStrategy(currentObj)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strategy {
+	case MergeStrategyThreeWay:
+		if err = r.setLastAppliedConfiguration(ctx, cr, desiredObj); err != nil {
+			return nil, err
+		}
+		return sdk.MergeObjectWithStore(ctx, desiredObj, currentObj, r.lastAppliedStore(cr))
+	case MergeStrategyAdditive:
+		return sdk.MergeObjectAdditive(desiredObj, currentObj)
+	case MergeStrategyReplace:
+		return sdk.ReplaceObject(desiredObj, currentObj)
+	case MergeStrategyMetadataOnly:
+		return currentObj, nil
+	}
+	return nil, fmt.Errorf("unknown merge strategy %q", strategy)
+}
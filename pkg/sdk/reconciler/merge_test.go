@@ -0,0 +1,114 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Merge strategies", func() {
+	deploymentGVK := appsv1.SchemeGroupVersion.WithKind("Deployment")
+	operatorDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	reconcileModifiedDeployment := func(strategy reconciler.MergeStrategy) *appsv1.Deployment {
+		args := createArgs(version)
+		if strategy != "" {
+			args.reconciler.WithMergeStrategy(deploymentGVK, strategy)
+		}
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Spec.Replicas = &[]int32{3}[0]
+		deployment.Spec.Paused = true
+		deployment.Spec.Template.Spec.Containers[0].Env = nil
+		Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+		doReconcile(args)
+
+		deployment, err = getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		return deployment
+	}
+
+	It("should merge three-way by default", func() {
+		deployment := reconcileModifiedDeployment("")
+
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(1))
+		Expect(deployment.Spec.Paused).To(BeTrue())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+	})
+
+	It("should only add missing fields with additive strategy", func() {
+		deployment := reconcileModifiedDeployment(reconciler.MergeStrategyAdditive)
+
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+		Expect(deployment.Spec.Paused).To(BeTrue())
+		// lists present in the live object are kept as they are
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+	})
+
+	It("should drop unknown fields with replace strategy", func() {
+		deployment := reconcileModifiedDeployment(reconciler.MergeStrategyReplace)
+
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(1))
+		Expect(deployment.Spec.Paused).To(BeFalse())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+	})
+
+	It("should leave content alone with metadata only strategy", func() {
+		deployment := reconcileModifiedDeployment(reconciler.MergeStrategyMetadataOnly)
+
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+	})
+
+	It("should keep config map data by default, but not when registered otherwise", func() {
+		for _, strategy := range []reconciler.MergeStrategy{"", reconciler.MergeStrategyThreeWay} {
+			args := createArgs(version)
+			configMap := createWaveConfigMap(nil)
+			args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{configMap}}, args.client, args.client.Scheme(), args.recorder).
+				WithController(args.mockController)
+			if strategy != "" {
+				args.reconciler.WithMergeStrategy(corev1.SchemeGroupVersion.WithKind("ConfigMap"), strategy)
+			}
+			doReconcile(args)
+
+			stored, err := getObject(args.client, configMap)
+			Expect(err).ToNot(HaveOccurred())
+			stored.(*corev1.ConfigMap).Data["key"] = "user value"
+			Expect(args.client.Update(context.TODO(), stored)).To(Succeed())
+
+			doReconcile(args)
+
+			stored, err = getObject(args.client, configMap)
+			Expect(err).ToNot(HaveOccurred())
+			if strategy == "" {
+				Expect(stored.(*corev1.ConfigMap).Data).To(HaveKeyWithValue("key", "user value"))
+			} else {
+				Expect(stored.(*corev1.ConfigMap).Data).To(HaveKeyWithValue("key", "value"))
+			}
+		}
+	})
+
+	It("should reject unknown strategy", func() {
+		args := createArgs(version)
+		Expect(func() { args.reconciler.WithMergeStrategy(deploymentGVK, "overwrite") }).To(Panic())
+	})
+})
@@ -0,0 +1,64 @@
+package reconciler
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// MetaStatusCrManager may be implemented by a CrManager whose CR status has metav1.Condition conditions; MetaStatus is
+// used instead of Status then, which may return nil
+type MetaStatusCrManager interface {
+	// MetaStatus extracts the status from the cr
+	MetaStatus(cr client.Object) *sdkapi.MetaStatus
+}
+
+// statusViewKey is the context key of the Status view of the MetaStatus of the reconciled CR
+type statusViewKey struct{}
+
+// statusView is the Status the Reconciler works with in place of the MetaStatus of the cr
+type statusView struct {
+	// cr is the CR whose MetaStatus is viewed, other objects have no view
+	cr client.Object
+	// status is converted from the MetaStatus of cr, and written back to it before cr is written
+	status *sdkapi.Status
+}
+
+// withStatusView returns a context carrying a Status view of the MetaStatus of the cr, converted once, unless ctx
+// carries one already. The returned function writes the view back to the MetaStatus, which is where the changes of
+// the view are left once the caller is done with them.
+func (r *Reconciler) withStatusView(ctx context.Context, cr client.Object) (context.Context, func()) {
+	manager, ok := r.crManager.(MetaStatusCrManager)
+	if !ok || r.statusView(ctx, cr) != nil {
+		return ctx, func() {}
+	}
+	view := &statusView{cr: cr, status: &sdkapi.Status{}}
+	sdk.StatusFromMeta(manager.MetaStatus(cr), view.status)
+	ctx = context.WithValue(ctx, statusViewKey{}, view)
+	return ctx, func() {
+		r.syncMetaStatus(ctx, cr)
+	}
+}
+
+// statusView returns the Status view of the MetaStatus of the cr carried by ctx, nil if there is none
+func (r *Reconciler) statusView(ctx context.Context, cr client.Object) *sdkapi.Status {
+	view, ok := ctx.Value(statusViewKey{}).(*statusView)
+	if !ok || view.cr != cr {
+		return nil
+	}
+	return view.status
+}
+
+// syncMetaStatus converts the Status view of the cr back into its MetaStatus before it is written
+func (r *Reconciler) syncMetaStatus(ctx context.Context, cr client.Object) {
+	manager, ok := r.crManager.(MetaStatusCrManager)
+	if !ok {
+		return
+	}
+	if view := r.statusView(ctx, cr); view != nil {
+		sdk.StatusToMeta(view, manager.MetaStatus(cr))
+	}
+}
@@ -0,0 +1,100 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+	"kubevirt.io/controller-lifecycle-operator-sdk/tests/mocks"
+)
+
+var _ = Describe("Meta status", func() {
+	var (
+		c      client.Client
+		r      *reconciler.Reconciler
+		config *testcr.MetaConfig
+	)
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+		Expect(testcr.AddToScheme(scheme.Scheme)).To(Succeed())
+		config = &testcr.MetaConfig{ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1}}
+		c = createClient(scheme.Scheme, config)
+		r = createReconcilerWithCrManager(&testcr.MetaConfigCrManager{}, c, scheme.Scheme, record.NewFakeRecorder(250)).
+			WithController(&mocks.MockController{})
+	})
+
+	reconcileMeta := func() {
+		_, err := r.ReconcileContext(context.TODO(), reconcileRequest(config.Name), version, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(config), config)).To(Succeed())
+	}
+
+	setDeploymentReady := func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		deployment.Status.Replicas = *deployment.Spec.Replicas
+		deployment.Status.ReadyReplicas = deployment.Status.Replicas
+		Expect(c.Status().Update(context.TODO(), deployment)).To(Succeed())
+	}
+
+	It("should drive the metav1 conditions", func() {
+		reconcileMeta()
+		Expect(config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		Expect(config.Status.OperatorVersion).To(Equal(version))
+		progressing := meta.FindStatusCondition(config.Status.Conditions, string(conditions.ConditionProgressing))
+		Expect(progressing).ToNot(BeNil())
+		Expect(progressing.Status).To(Equal(metav1.ConditionTrue))
+		Expect(progressing.Reason).To(Equal("DeployStarted"))
+		Expect(progressing.ObservedGeneration).To(Equal(int64(1)))
+		degraded := meta.FindStatusCondition(config.Status.Conditions, string(conditions.ConditionDegraded))
+		Expect(degraded.Reason).To(Equal(sdk.UnspecifiedReason))
+
+		setDeploymentReady()
+		reconcileMeta()
+		Expect(config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(config.Status.ObservedGeneration).To(Equal(int64(1)))
+		available := meta.FindStatusCondition(config.Status.Conditions, string(conditions.ConditionAvailable))
+		Expect(available.Status).To(Equal(metav1.ConditionTrue))
+		Expect(available.Reason).To(Equal("DeployCompleted"))
+		Expect(meta.IsStatusConditionFalse(config.Status.Conditions, string(conditions.ConditionProgressing))).To(BeTrue())
+	})
+
+	It("should keep the generation of the conditions left unchanged", func() {
+		reconcileMeta()
+		config.Generation = 2
+		Expect(c.Update(context.TODO(), config)).To(Succeed())
+		setDeploymentReady()
+		reconcileMeta()
+		Expect(config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		// Available and Progressing changed with the deployment completed, Degraded didn't
+		Expect(meta.FindStatusCondition(config.Status.Conditions, string(conditions.ConditionAvailable)).ObservedGeneration).To(Equal(int64(2)))
+		Expect(meta.FindStatusCondition(config.Status.Conditions, string(conditions.ConditionProgressing)).ObservedGeneration).To(Equal(int64(2)))
+		Expect(meta.FindStatusCondition(config.Status.Conditions, string(conditions.ConditionDegraded)).ObservedGeneration).To(Equal(int64(1)))
+		deployed := config.Status.DeepCopy()
+
+		config.Generation = 3
+		Expect(c.Update(context.TODO(), config)).To(Succeed())
+		reconcileMeta()
+		Expect(config.Status.ObservedGeneration).To(Equal(int64(3)))
+		for _, condition := range config.Status.Conditions {
+			Expect(condition.ObservedGeneration).To(Equal(meta.FindStatusCondition(deployed.Conditions, condition.Type).ObservedGeneration))
+		}
+	})
+})
@@ -0,0 +1,70 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileResources reconciles the given managed resources, concurrently if the Reconciler is configured so. The
+// failed create and update calls are returned in the order of the resources; any other error aborts the
+// reconciliation and is returned as the second value.
+func (r *Reconciler) reconcileResources(ctx context.Context, logger logr.Logger, cr client.Object, resources []client.Object, operatorVersion string) ([]error, error) {
+	if r.resourceConcurrency <= 1 || len(resources) <= 1 {
+		var applyErrors []error
+		for _, desiredObj := range resources {
+			if err := r.reconcileResource(ctx, logger, cr, desiredObj, operatorVersion); err != nil {
+				if !isResourceError(err) {
+					return nil, err
+				}
+				applyErrors = append(applyErrors, err)
+			}
+		}
+		return applyErrors, nil
+	}
+
+	results := make([]error, len(resources))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(r.resourceConcurrency)
+	for i, desiredObj := range resources {
+		i, desiredObj := i, desiredObj
+		g.Go(func() error {
+			// don't start on further resources once a fatal error occurred
+			if gctx.Err() != nil {
+				return nil
+			}
+			// interleaved log lines have to identify the resource they belong to
+			objLogger := logger.WithValues(
+				"namespace", desiredObj.GetNamespace(),
+				"name", desiredObj.GetName(),
+				"type", fmt.Sprintf("%T", desiredObj))
+			results[i] = r.reconcileResource(gctx, objLogger, cr, desiredObj, operatorVersion)
+			if results[i] != nil && !isResourceError(results[i]) {
+				return results[i]
+			}
+			return nil
+		})
+	}
+	// the first fatal error, the others may be caused by the cancellation
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var applyErrors []error
+	for _, err := range results {
+		if err != nil {
+			applyErrors = append(applyErrors, err)
+		}
+	}
+	return applyErrors, nil
+}
+
+// isResourceError checks whether err is the failure of a single resource, which doesn't abort the reconciliation
+func isResourceError(err error) bool {
+	var resourceErr *ResourceError
+	return errors.As(err, &resourceErr)
+}
@@ -0,0 +1,135 @@
+package reconciler_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Concurrent resource reconciliation", func() {
+	const resourceCount = 6
+
+	var (
+		lock   sync.Mutex
+		states map[string][]callbacks.ReconcileState
+	)
+
+	BeforeEach(func() {
+		states = map[string][]callbacks.ReconcileState{}
+		invokeCallbacks = func(_ interface{}, state callbacks.ReconcileState, desiredObj client.Object, _ client.Object) error {
+			lock.Lock()
+			defer lock.Unlock()
+			states[desiredObj.GetName()] = append(states[desiredObj.GetName()], state)
+			return nil
+		}
+	})
+
+	createConcurrentArgs := func(c client.Client) *args {
+		args := createArgs(version)
+		if c != nil {
+			args.client = c
+		}
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: createConfigMaps(resourceCount)}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithResourceConcurrency(3)
+		return args
+	}
+
+	It("should reconcile resources concurrently", func() {
+		var inFlight, maxInFlight int
+		var mu sync.Mutex
+		c := interceptor.NewClient(createArgs(version).client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		args := createConcurrentArgs(c)
+		doReconcile(args)
+
+		Expect(maxInFlight).To(BeNumerically(">", 1))
+		Expect(maxInFlight).To(BeNumerically("<=", 3))
+		for _, cm := range createConfigMaps(resourceCount) {
+			_, err := getObject(args.client, cm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(states[cm.GetName()]).To(Equal([]callbacks.ReconcileState{
+				callbacks.ReconcileStatePreCreate,
+				callbacks.ReconcileStatePostCreate,
+			}))
+		}
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should aggregate failed creates", func() {
+		c := interceptor.NewClient(createArgs(version).client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == "config-1" || obj.GetName() == "config-4" {
+					return fmt.Errorf("create failed")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		args := createConcurrentArgs(c)
+
+		_, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), version, log)
+		Expect(err).To(MatchError(HavePrefix("reconcile encountered 2 errors")))
+
+		for _, cm := range createConfigMaps(resourceCount) {
+			_, err := getObject(args.client, cm)
+			if cm.GetName() == "config-1" || cm.GetName() == "config-4" {
+				Expect(err).To(HaveOccurred())
+				Expect(states[cm.GetName()]).To(Equal([]callbacks.ReconcileState{callbacks.ReconcileStatePreCreate}))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+	})
+
+	It("should abort on fatal error", func() {
+		args := createConcurrentArgs(nil)
+		invokeCallbacks = func(_ interface{}, state callbacks.ReconcileState, desiredObj client.Object, _ client.Object) error {
+			if desiredObj.GetName() == "config-2" {
+				return fmt.Errorf("callback failed")
+			}
+			return nil
+		}
+
+		_, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), version, log)
+		Expect(err).To(MatchError("callback failed"))
+	})
+})
+
+func createConfigMaps(count int) []client.Object {
+	var result []client.Object
+	for i := 0; i < count; i++ {
+		result = append(result, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("config-%d", i),
+				Namespace: testcr.Namespace,
+			},
+		})
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+const (
+	// PausedAnnotation is the name of the annotation (under the annotation prefix) pausing the reconciliation of the
+	// CR when set to "true": no managed resource is created, updated or deleted and no upgrade is started, but the
+	// status is still reported and the CR can still be deleted
+	PausedAnnotation = "paused"
+
+	// ConditionPaused is set while the reconciliation of the CR is paused
+	ConditionPaused conditions.ConditionType = "Paused"
+
+	reconcilePaused  = "ReconcilePaused"
+	reconcileResumed = "ReconcileResumed"
+)
+
+// isPaused checks whether the cr asks for the reconciliation to be paused
+func (r *Reconciler) isPaused(cr client.Object) bool {
+	return cr.GetAnnotations()[r.annotation(PausedAnnotation)] == "true"
+}
+
+// reconcilePaused only reports the status of the paused cr
+func (r *Reconciler) reconcilePaused(ctx context.Context, logger logr.Logger, cr client.Object) (reconcile.Result, error) {
+	status := r.status(ctx, cr)
+	currentConditionValues := sdk.GetConditionValues(status.Conditions)
+
+	if !conditions.IsStatusConditionTrue(status.Conditions, ConditionPaused) {
+		message := fmt.Sprintf("Reconciliation paused by the %s annotation", r.annotation(PausedAnnotation))
+		conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+			Type:    ConditionPaused,
+			Status:  corev1.ConditionTrue,
+			Reason:  reconcilePaused,
+			Message: message,
+		})
+		r.recorder.Event(cr, corev1.EventTypeNormal, reconcilePaused, message)
+	}
+
+	if status.Phase != "" {
+		if _, err := r.CheckDegradedContext(ctx, logger, cr); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if sdk.ConditionsChanged(currentConditionValues, sdk.GetConditionValues(status.Conditions)) {
+		if err := r.CrUpdateStatusContext(ctx, status.Phase, cr); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// clearPaused removes the ConditionPaused condition once the reconciliation is resumed
+func (r *Reconciler) clearPaused(ctx context.Context, cr client.Object) {
+	status := r.status(ctx, cr)
+	if conditions.FindStatusCondition(status.Conditions, ConditionPaused) == nil {
+		return
+	}
+	conditions.RemoveStatusCondition(&status.Conditions, ConditionPaused)
+	r.recorder.Event(cr, corev1.EventTypeNormal, reconcileResumed, "Reconciliation resumed")
+}
@@ -0,0 +1,98 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Paused reconciliation", func() {
+	paused := reconciler.DefaultAnnotationPrefix + "/" + reconciler.PausedAnnotation
+	operatorDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	setPaused := func(args *args, value string) {
+		args.config.SetAnnotations(map[string]string{paused: value})
+		Expect(args.client.Update(context.TODO(), args.config)).To(Succeed())
+	}
+
+	deployPaused := func() *args {
+		args := createArgs(version)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		drainEvents(args.recorder)
+
+		setPaused(args, "true")
+		return args
+	}
+
+	It("should not reconcile resources while paused", func() {
+		args := deployPaused()
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Spec.Template.Spec.Containers[0].Env = nil
+		Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+
+		doReconcile(args)
+
+		deployment, err = getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+		pausedCondition := v1.FindStatusCondition(args.config.Status.Conditions, reconciler.ConditionPaused)
+		Expect(pausedCondition).ToNot(BeNil())
+		Expect(pausedCondition.Status).To(BeEquivalentTo("True"))
+		Expect(drainEvents(args.recorder)).To(ConsistOf("Normal ReconcilePaused Reconciliation paused by the " + paused + " annotation"))
+
+		setPaused(args, "false")
+		doReconcile(args)
+
+		deployment, err = getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+		Expect(v1.FindStatusCondition(args.config.Status.Conditions, reconciler.ConditionPaused)).To(BeNil())
+		Expect(drainEvents(args.recorder)).To(ContainElement("Normal ReconcileResumed Reconciliation resumed"))
+	})
+
+	It("should report degraded status while paused", func() {
+		args := deployPaused()
+
+		setDeploymentsDegraded(args)
+
+		Expect(v1.IsStatusConditionTrue(args.config.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+		Expect(v1.IsStatusConditionTrue(args.config.Status.Conditions, reconciler.ConditionPaused)).To(BeTrue())
+	})
+
+	It("should not upgrade while paused", func() {
+		args := deployPaused()
+
+		args.version = "v0.0.2"
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(args.config.Status.ObservedVersion).To(Equal(version))
+	})
+
+	It("should delete while paused", func() {
+		args := deployPaused()
+		doReconcile(args)
+
+		Expect(args.client.Delete(context.TODO(), args.config)).To(Succeed())
+		doReconcileExpectDelete(args)
+	})
+})
@@ -0,0 +1,236 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+const (
+	// PlanOnlyAnnotation is the name of the annotation (under the annotation prefix) switching the reconciliation of
+	// the CR to the plan only mode when set to "true": the pending changes are reported in the ConditionPlanned
+	// condition, but not applied
+	PlanOnlyAnnotation = "plan-only"
+
+	// ConditionPlanned reports the changes the reconciliation would make in the plan only mode
+	ConditionPlanned conditions.ConditionType = "Planned"
+
+	changesPending = "ChangesPending"
+	noChanges      = "NoChanges"
+
+	// maxPlanMessageChanges limits the number of changes listed in the ConditionPlanned message
+	maxPlanMessageChanges = 10
+)
+
+// PlannedOperation is the kind of write the reconciliation would perform on a managed resource
+type PlannedOperation string
+
+const (
+	// PlannedCreate means the resource would be created
+	PlannedCreate PlannedOperation = "Create"
+	// PlannedUpdate means the resource would be updated
+	PlannedUpdate PlannedOperation = "Update"
+	// PlannedDelete means the resource would be deleted as no longer used
+	PlannedDelete PlannedOperation = "Delete"
+)
+
+// PlannedChange describes a single write the reconciliation would perform
+type PlannedChange struct {
+	Operation PlannedOperation
+	// Object is the object that would be created or deleted, or the state the object would be updated to
+	Object client.Object
+	// Patch is the JSON patch from the current to the updated object, set for updates only
+	Patch string
+}
+
+// Plan lists the changes the reconciliation of a CR would perform
+type Plan struct {
+	Changes []PlannedChange
+}
+
+// Empty checks whether the reconciliation would leave all managed resources as they are
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes of given operation
+func (p *Plan) Count(operation PlannedOperation) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Operation == operation {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary describes the plan in a human readable form
+func (p *Plan) Summary() string {
+	if p.Empty() {
+		return "No changes pending"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d to create, %d to update, %d to delete", p.Count(PlannedCreate), p.Count(PlannedUpdate), p.Count(PlannedDelete))
+	for i, change := range p.Changes {
+		if i == maxPlanMessageChanges {
+			fmt.Fprintf(&sb, "; and %d more", len(p.Changes)-i)
+			break
+		}
+		sep := ": "
+		if i > 0 {
+			sep = ", "
+		}
+		fmt.Fprintf(&sb, "%s%s %T %s", sep, change.Operation, change.Object, objectName(change.Object))
+	}
+	return sb.String()
+}
+
+// Plan computes the changes ReconcileUpdate and CleanupUnusedResources would perform for the cr without writing
+// anything. Callbacks are not invoked and apply waves are not waited for.
+func (r *Reconciler) Plan(ctx context.Context, cr client.Object, operatorVersion string) (*Plan, error) {
+	ctx = context.WithValue(ctx, planningKey{}, true)
+	desiredResources, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	for _, desiredObj := range desiredResources {
+		change, err := r.planResource(ctx, cr, desiredObj, operatorVersion)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			plan.Changes = append(plan.Changes, *change)
+		}
+	}
+
+	unusedResources, err := r.getUnusedResources(ctx, r.log, cr)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range unusedResources {
+		plan.Changes = append(plan.Changes, PlannedChange{Operation: PlannedDelete, Object: obj})
+	}
+
+	return plan, nil
+}
+
+// planResource computes the change reconcileResource would perform on desiredObj, nil if none
+func (r *Reconciler) planResource(ctx context.Context, cr client.Object, desiredObj client.Object, operatorVersion string) (*PlannedChange, error) {
+	currentObj := sdk.NewDefaultInstance(desiredObj)
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(desiredObj), currentObj); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err = r.prepareForCreate(ctx, cr, desiredObj, operatorVersion); err != nil {
+			return nil, err
+		}
+		return &PlannedChange{Operation: PlannedCreate, Object: desiredObj}, nil
+	}
+
+	policy, err := r.reconcilePolicy(desiredObj, currentObj)
+	if err != nil {
+		return nil, err
+	}
+	if policy != ReconcilePolicyEnforce {
+		return nil, nil
+	}
+
+	currentObj, err = sdk.StripStatusFromObject(currentObj)
+	if err != nil {
+		return nil, err
+	}
+	currentObjCopy := currentObj.DeepCopyObject().(client.Object)
+
+	updatedObj, _, err := r.updatedState(ctx, cr, desiredObj, currentObj, operatorVersion)
+	if err != nil {
+		return nil, err
+	}
+	unchanged, _, err := r.compareStates(ctx, currentObjCopy, updatedObj)
+	if err != nil || unchanged {
+		return nil, err
+	}
+
+	patch, err := sdk.CreateJSONPatch(currentObjCopy, updatedObj)
+	if err != nil {
+		return nil, err
+	}
+	sdk.SetLabel(r.updateVersionLabel, operatorVersion, updatedObj)
+	return &PlannedChange{Operation: PlannedUpdate, Object: updatedObj, Patch: string(patch)}, nil
+}
+
+// planningKey marks the context of Plan, in which nothing must be written
+type planningKey struct{}
+
+// isPlanning checks whether ctx is the one of Plan
+func isPlanning(ctx context.Context) bool {
+	planning, _ := ctx.Value(planningKey{}).(bool)
+	return planning
+}
+
+// isPlanOnly checks whether the cr asks for the plan only mode
+func (r *Reconciler) isPlanOnly(cr client.Object) bool {
+	return cr.GetAnnotations()[r.annotation(PlanOnlyAnnotation)] == "true"
+}
+
+// reconcilePlanOnly reports the pending changes in the ConditionPlanned condition instead of applying them
+func (r *Reconciler) reconcilePlanOnly(ctx context.Context, logger logr.Logger, cr client.Object, operatorVersion string) (reconcile.Result, error) {
+	plan, err := r.Plan(ctx, cr, operatorVersion)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	for _, change := range plan.Changes {
+		logger.Info("Planned change",
+			"operation", change.Operation,
+			"namespace", change.Object.GetNamespace(),
+			"name", change.Object.GetName(),
+			"type", fmt.Sprintf("%T", change.Object),
+			"patch", change.Patch)
+	}
+
+	reason := noChanges
+	if !plan.Empty() {
+		reason = changesPending
+	}
+	condition := conditions.Condition{
+		Type:    ConditionPlanned,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: plan.Summary(),
+	}
+
+	status := r.status(ctx, cr)
+	current := conditions.FindStatusCondition(status.Conditions, ConditionPlanned)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return reconcile.Result{}, nil
+	}
+
+	conditions.SetStatusCondition(&status.Conditions, condition)
+	r.recorder.Event(cr, corev1.EventTypeNormal, reason, condition.Message)
+	return reconcile.Result{}, r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
+
+// clearPlanned removes the ConditionPlanned condition once the plan only mode is over
+func (r *Reconciler) clearPlanned(ctx context.Context, cr client.Object) {
+	status := r.status(ctx, cr)
+	conditions.RemoveStatusCondition(&status.Conditions, ConditionPlanned)
+}
+
+func objectName(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
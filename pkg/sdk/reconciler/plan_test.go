@@ -0,0 +1,111 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Plan", func() {
+	operatorDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	It("should plan creation without creating anything", func() {
+		args := createArgs(version)
+
+		plan, err := args.reconciler.Plan(context.TODO(), args.config, version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Changes).To(HaveLen(1))
+		Expect(plan.Changes[0].Operation).To(Equal(reconciler.PlannedCreate))
+		Expect(plan.Changes[0].Object.GetName()).To(Equal(testcr.OperatorDeploymentName))
+		Expect(plan.Changes[0].Object.GetLabels()).To(HaveKeyWithValue(createVersionLabel, version))
+
+		_, err = getDeployment(args.client, operatorDeployment)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should plan update and deletion without writing", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		plan, err := args.reconciler.Plan(context.TODO(), args.config, version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Empty()).To(BeTrue())
+		Expect(plan.Summary()).To(Equal("No changes pending"))
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Spec.Template.Spec.Containers[0].Env = nil
+		Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+		modifiedVersion := deployment.ResourceVersion
+
+		unused := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      "unused",
+			Namespace: testcr.Namespace,
+			Labels:    map[string]string{createVersionLabel: version},
+		}}
+		Expect(controllerutil.SetControllerReference(args.config, unused, scheme.Scheme)).To(Succeed())
+		Expect(args.client.Create(context.TODO(), unused)).To(Succeed())
+
+		plan, err = args.reconciler.Plan(context.TODO(), args.config, version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Changes).To(HaveLen(2))
+		Expect(plan.Changes[0].Operation).To(Equal(reconciler.PlannedUpdate))
+		Expect(plan.Changes[0].Patch).To(ContainSubstring("/spec/template/spec/containers/0/env"))
+		Expect(plan.Changes[1].Operation).To(Equal(reconciler.PlannedDelete))
+		Expect(plan.Changes[1].Object.GetName()).To(Equal("unused"))
+		Expect(plan.Summary()).To(Equal("0 to create, 1 to update, 1 to delete: Update *v1.Deployment " + testcr.Namespace + "/" + testcr.OperatorDeploymentName + ", Delete *v1.Deployment " + testcr.Namespace + "/unused"))
+
+		deployment, err = getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.ResourceVersion).To(Equal(modifiedVersion))
+		_, err = getDeployment(args.client, unused)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should only report changes while the plan only annotation is set", func() {
+		args := createArgs(version)
+		planOnly := reconciler.DefaultAnnotationPrefix + "/" + reconciler.PlanOnlyAnnotation
+		args.config.SetAnnotations(map[string]string{planOnly: "true"})
+		Expect(args.client.Update(context.TODO(), args.config)).To(Succeed())
+
+		doReconcile(args)
+
+		_, err := getDeployment(args.client, operatorDeployment)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseEmpty))
+		planned := v1.FindStatusCondition(args.config.Status.Conditions, reconciler.ConditionPlanned)
+		Expect(planned).ToNot(BeNil())
+		Expect(planned.Reason).To(Equal("ChangesPending"))
+		Expect(planned.Message).To(ContainSubstring("1 to create"))
+
+		args.config.SetAnnotations(nil)
+		Expect(args.client.Update(context.TODO(), args.config)).To(Succeed())
+
+		doReconcile(args)
+
+		_, err = getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(v1.FindStatusCondition(args.config.Status.Conditions, reconciler.ConditionPlanned)).To(BeNil())
+	})
+})
@@ -0,0 +1,144 @@
+package reconciler
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// ReconcilePolicy decides how the Reconciler treats an existing managed resource
+type ReconcilePolicy string
+
+const (
+	// ReconcilePolicyEnforce keeps the resource in the desired state, the default
+	ReconcilePolicyEnforce ReconcilePolicy = "enforce"
+	// ReconcilePolicyCreateOnly creates the resource, but never updates or deletes it afterwards
+	ReconcilePolicyCreateOnly ReconcilePolicy = "create-only"
+	// ReconcilePolicyUnmanaged leaves the resource to the admin, who has taken it over
+	ReconcilePolicyUnmanaged ReconcilePolicy = "unmanaged"
+)
+
+const (
+	// ReconcilePolicyAnnotation is the name of the annotation (under the annotation prefix) setting the
+	// ReconcilePolicy of a managed resource. It is read from the desired object, then from the live object, so that
+	// admins can take over resources the operator declares no policy for.
+	ReconcilePolicyAnnotation = "reconcile-policy"
+
+	// IgnoreFieldsAnnotation is the name of the annotation (under the annotation prefix) listing comma separated
+	// JSON pointers (RFC 6901) to the fields of a managed resource the Reconciler never changes, e.g.
+	// "/spec/replicas". Like the ReconcilePolicyAnnotation, it is read from the desired object first.
+	IgnoreFieldsAnnotation = "ignore-fields"
+)
+
+// lookupAnnotation returns the annotation of the desired object, falling back to the live one
+func (r *Reconciler) lookupAnnotation(name string, desiredObj, currentObj client.Object) (string, bool) {
+	key := r.annotation(name)
+	if desiredObj != nil {
+		if value, ok := desiredObj.GetAnnotations()[key]; ok {
+			return value, true
+		}
+	}
+	if currentObj != nil {
+		if value, ok := currentObj.GetAnnotations()[key]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// reconcilePolicy returns the ReconcilePolicy of the resource, either of the objects may be nil
+func (r *Reconciler) reconcilePolicy(desiredObj, currentObj client.Object) (ReconcilePolicy, error) {
+	value, ok := r.lookupAnnotation(ReconcilePolicyAnnotation, desiredObj, currentObj)
+	if !ok {
+		return ReconcilePolicyEnforce, nil
+	}
+	switch policy := ReconcilePolicy(value); policy {
+	case ReconcilePolicyEnforce, ReconcilePolicyCreateOnly, ReconcilePolicyUnmanaged:
+		return policy, nil
+	}
+	obj := currentObj
+	if obj == nil {
+		obj = desiredObj
+	}
+	return "", fmt.Errorf("unknown reconcile policy %q of %T %s", value, obj, obj.GetName())
+}
+
+// isRetained checks whether the live obj has to be kept even when no longer desired
+func (r *Reconciler) isRetained(obj client.Object) bool {
+	policy, err := r.reconcilePolicy(nil, obj)
+	// unknown policies are treated as the safer option
+	return err != nil || policy != ReconcilePolicyEnforce
+}
+
+// ignoredFields returns the paths of the fields the Reconciler must not change
+func (r *Reconciler) ignoredFields(desiredObj, currentObj client.Object) ([][]string, error) {
+	value, ok := r.lookupAnnotation(IgnoreFieldsAnnotation, desiredObj, currentObj)
+	if !ok {
+		return nil, nil
+	}
+
+	var paths [][]string
+	for _, pointer := range strings.Split(value, ",") {
+		if pointer = strings.TrimSpace(pointer); pointer == "" {
+			continue
+		}
+		path, err := parseFieldPointer(pointer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignored field of %T %s: %v", currentObj, currentObj.GetName(), err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// parseFieldPointer splits the JSON pointer to a field into the unescaped field names
+func parseFieldPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") || pointer == "/" {
+		return nil, fmt.Errorf("%q is not a JSON pointer to a field", pointer)
+	}
+	path := strings.Split(pointer[1:], "/")
+	for i, name := range path {
+		path[i] = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	}
+	return path, nil
+}
+
+// keepFields returns a copy of obj with the fields at paths set as in originalObj
+func keepFields(obj, originalObj client.Object, paths [][]string) (client.Object, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	originalContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(originalObj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		value, found, err := unstructured.NestedFieldCopy(originalContent, path...)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			if err = unstructured.SetNestedField(content, value, path...); err != nil {
+				return nil, err
+			}
+		} else {
+			unstructured.RemoveNestedField(content, path...)
+		}
+	}
+
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		return &unstructured.Unstructured{Object: content}, nil
+	}
+	result := sdk.NewDefaultInstance(obj)
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(content, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
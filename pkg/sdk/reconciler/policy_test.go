@@ -0,0 +1,160 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Reconcile policies", func() {
+	policyAnnotation := reconciler.DefaultAnnotationPrefix + "/" + reconciler.ReconcilePolicyAnnotation
+	ignoreFieldsAnnotation := reconciler.DefaultAnnotationPrefix + "/" + reconciler.IgnoreFieldsAnnotation
+	operatorDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	modifyDeployment := func(args *args, annotations map[string]string) {
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		for key, value := range annotations {
+			deployment.Annotations[key] = value
+		}
+		deployment.Spec.Replicas = &[]int32{3}[0]
+		deployment.Spec.Template.Spec.Containers[0].Env = nil
+		Expect(args.client.Update(context.TODO(), deployment)).To(Succeed())
+	}
+
+	table := []struct {
+		policy  reconciler.ReconcilePolicy
+		ignored bool
+	}{
+		{reconciler.ReconcilePolicyUnmanaged, true},
+		{reconciler.ReconcilePolicyCreateOnly, true},
+		{reconciler.ReconcilePolicyEnforce, false},
+	}
+	for _, entry := range table {
+		entry := entry
+		It("should honour the "+string(entry.policy)+" policy of the live object", func() {
+			args := createArgs(version)
+			doReconcile(args)
+			modifyDeployment(args, map[string]string{policyAnnotation: string(entry.policy)})
+
+			doReconcile(args)
+
+			deployment, err := getDeployment(args.client, operatorDeployment)
+			Expect(err).ToNot(HaveOccurred())
+			if entry.ignored {
+				Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+				Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+			} else {
+				Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(1))
+				Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+			}
+		})
+	}
+
+	It("should never touch ignored fields", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		modifyDeployment(args, map[string]string{ignoreFieldsAnnotation: "/spec/replicas, /spec/paused"})
+
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(HaveLen(1))
+	})
+
+	It("should never touch ignored fields with escaped names", func() {
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(deployment *appsv1.Deployment) {
+			deployment.Annotations = map[string]string{"example.com/owner": "operator"}
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		doReconcile(args)
+		modifyDeployment(args, map[string]string{
+			ignoreFieldsAnnotation: "/metadata/annotations/example.com~1owner",
+			"example.com/owner":    "admin",
+		})
+
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.Annotations).To(HaveKeyWithValue("example.com/owner", "admin"))
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(1))
+	})
+
+	It("should prefer the policy of the desired object", func() {
+		args := createArgs(version)
+		crManager := &mutatingCrManager{mutate: func(deployment *appsv1.Deployment) {
+			deployment.Annotations = map[string]string{policyAnnotation: string(reconciler.ReconcilePolicyEnforce)}
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		doReconcile(args)
+		modifyDeployment(args, map[string]string{policyAnnotation: string(reconciler.ReconcilePolicyUnmanaged)})
+
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(1))
+		Expect(deployment.Annotations).To(HaveKeyWithValue(policyAnnotation, string(reconciler.ReconcilePolicyEnforce)))
+	})
+
+	It("should fail on ignored fields which are not JSON pointers", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		modifyDeployment(args, map[string]string{ignoreFieldsAnnotation: "spec.replicas"})
+
+		doReconcileError(args)
+	})
+
+	It("should keep unused resource which is not enforced", func() {
+		args := createArgs(version)
+		doReconcile(args)
+
+		retained := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:        "retained",
+			Namespace:   testcr.Namespace,
+			Labels:      map[string]string{createVersionLabel: version},
+			Annotations: map[string]string{policyAnnotation: string(reconciler.ReconcilePolicyCreateOnly)},
+		}}
+		Expect(controllerutil.SetControllerReference(args.config, retained, scheme.Scheme)).To(Succeed())
+		Expect(args.client.Create(context.TODO(), retained)).To(Succeed())
+
+		plan, err := args.reconciler.Plan(context.TODO(), args.config, version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Count(reconciler.PlannedDelete)).To(BeZero())
+
+		Expect(args.reconciler.CleanupUnusedResourcesContext(context.TODO(), log, args.config)).To(Succeed())
+		_, err = getDeployment(args.client, retained)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail on unknown policy", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		modifyDeployment(args, map[string]string{policyAnnotation: "sometimes"})
+
+		doReconcileError(args)
+	})
+})
@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// APIServiceGVK identifies the aggregated API registrations, which are checked in their unstructured form
+var APIServiceGVK = schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"}
+
+// ReadinessChecker checks whether a managed resource is ready. obj is the resource as read from the cluster, reader
+// allows to look up related objects.
+type ReadinessChecker func(ctx context.Context, reader client.Reader, obj client.Object) (bool, error)
+
+// DefaultReadinessCheckers returns the readiness checkers the Reconciler is created with: only Deployments are checked
+// by default, the other BuiltinReadinessCheckers are enabled with WithBuiltinReadinessCheckers
+func DefaultReadinessCheckers() map[schema.GroupVersionKind]ReadinessChecker {
+	return map[schema.GroupVersionKind]ReadinessChecker{
+		appsv1.SchemeGroupVersion.WithKind("Deployment"): typedReadinessChecker(sdk.CheckDeploymentReady),
+	}
+}
+
+// BuiltinReadinessCheckers returns the readiness checkers provided by the package. The Service checker reads the
+// Endpoints of the Service, which requires the permission to get, list and watch endpoints when the client of the
+// Reconciler is cached.
+func BuiltinReadinessCheckers() map[schema.GroupVersionKind]ReadinessChecker {
+	return map[schema.GroupVersionKind]ReadinessChecker{
+		appsv1.SchemeGroupVersion.WithKind("Deployment"):                                      typedReadinessChecker(sdk.CheckDeploymentReady),
+		appsv1.SchemeGroupVersion.WithKind("DaemonSet"):                                       typedReadinessChecker(sdk.CheckDaemonSetReady),
+		appsv1.SchemeGroupVersion.WithKind("StatefulSet"):                                     typedReadinessChecker(sdk.CheckStatefulSetReady),
+		batchv1.SchemeGroupVersion.WithKind("Job"):                                            typedReadinessChecker(sdk.CheckJobComplete),
+		extv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"):                         typedReadinessChecker(sdk.CheckCRDEstablished),
+		admissionregistrationv1.SchemeGroupVersion.WithKind("ValidatingWebhookConfiguration"): typedReadinessChecker(sdk.CheckValidatingWebhookCABundle),
+		admissionregistrationv1.SchemeGroupVersion.WithKind("MutatingWebhookConfiguration"):   typedReadinessChecker(sdk.CheckMutatingWebhookCABundle),
+		corev1.SchemeGroupVersion.WithKind("Service"):                                         checkServiceReady,
+		APIServiceGVK: func(_ context.Context, _ client.Reader, obj client.Object) (bool, error) {
+			return sdk.CheckAPIServiceAvailable(obj)
+		},
+	}
+}
+
+// typedReadinessChecker adapts a check of a typed object to ReadinessChecker
+func typedReadinessChecker[T client.Object](check func(T) bool) ReadinessChecker {
+	return func(_ context.Context, _ client.Reader, obj client.Object) (bool, error) {
+		typedObj, ok := obj.(T)
+		if !ok {
+			return false, fmt.Errorf("unexpected type %T of %s", obj, obj.GetName())
+		}
+		return check(typedObj), nil
+	}
+}
+
+// checkServiceReady checks whether a service selecting pods has at least one ready endpoint
+func checkServiceReady(ctx context.Context, reader client.Reader, obj client.Object) (bool, error) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T of %s", obj, obj.GetName())
+	}
+	if len(service.Spec.Selector) == 0 || service.Spec.Type == corev1.ServiceTypeExternalName {
+		return true, nil
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(service), endpoints); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return sdk.CheckEndpointsReady(endpoints), nil
+}
+
+// isResourceReady checks whether the cluster counterpart of obj is ready. Resources without a registered readiness
+// checker or health expression are ready as soon as they exist; resources with an invalid health expression are not
+// ready, which is reported with an event on the cr.
+func (r *Reconciler) isResourceReady(ctx context.Context, cr client.Object, obj client.Object) (bool, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return false, err
+	}
+
+	currentObj := sdk.NewDefaultInstance(obj)
+	if u, ok := currentObj.(*unstructured.Unstructured); ok {
+		u.SetGroupVersionKind(gvk)
+	}
+	if err = r.client.Get(ctx, client.ObjectKeyFromObject(obj), currentObj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if checker, ok := r.readinessCheckers[gvk]; ok {
+		ready, err := checker(ctx, r.client, currentObj)
+		if err != nil || !ready {
+			return false, err
+		}
+	}
+
+	if expression := r.healthExpression(obj, gvk); expression != "" {
+		healthy, err := r.healthExpressions.evaluate(expression, currentObj)
+		if err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, invalidHealthExpression, fmt.Sprintf("Resource %T %s/%s is not ready: %v", obj, obj.GetNamespace(), obj.GetName(), err))
+			return false, nil
+		}
+		return healthy, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,136 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Readiness checks", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	It("should report degraded DaemonSet", func() {
+		args := createArgs(version)
+		daemonSet := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "handler", Namespace: testcr.Namespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "handler"}},
+			},
+		}
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{daemonSet}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithBuiltinReadinessCheckers(appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+
+		doReconcile(args)
+		setDaemonSetStatus(args, daemonSet, appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 2})
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(v1.IsStatusConditionFalse(args.config.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+
+		setDaemonSetStatus(args, daemonSet, appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 1, NumberUnavailable: 1})
+		doReconcile(args)
+		Expect(v1.IsStatusConditionTrue(args.config.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+
+		setDaemonSetStatus(args, daemonSet, appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 2})
+		doReconcile(args)
+		Expect(v1.IsStatusConditionFalse(args.config.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should use registered readiness checker", func() {
+		args := createArgs(version)
+		configMap := createWaveConfigMap(nil)
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{configMap}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithReadinessChecker(corev1.SchemeGroupVersion.WithKind("ConfigMap"), func(_ context.Context, _ client.Reader, obj client.Object) (bool, error) {
+				return obj.GetAnnotations()["ready"] == "true", nil
+			})
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+
+		storedObj, err := getObject(args.client, configMap)
+		Expect(err).ToNot(HaveOccurred())
+		storedObj.SetAnnotations(map[string]string{"ready": "true"})
+		Expect(args.client.Update(context.TODO(), storedObj)).To(Succeed())
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should wait for Service endpoints", func() {
+		args := createArgs(version)
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: testcr.Namespace},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "api"},
+			},
+		}
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{service}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithBuiltinReadinessCheckers()
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+
+		endpoints := &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: testcr.Namespace},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+		}
+		Expect(args.client.Create(context.TODO(), endpoints)).To(Succeed())
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+})
+
+var _ = Describe("Default readiness checks", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	It("should only check Deployments unless enabled", func() {
+		args := createArgs(version)
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: testcr.Namespace},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "api"},
+			},
+		}
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{service}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should refuse unknown built-in readiness checkers", func() {
+		args := createArgs(version)
+		Expect(func() { args.reconciler.WithBuiltinReadinessCheckers(corev1.SchemeGroupVersion.WithKind("ConfigMap")) }).To(Panic())
+	})
+})
+
+func setDaemonSetStatus(args *args, daemonSet *appsv1.DaemonSet, status appsv1.DaemonSetStatus) {
+	storedObj, err := getObject(args.client, daemonSet)
+	Expect(err).ToNot(HaveOccurred())
+	stored := storedObj.(*appsv1.DaemonSet)
+	status.ObservedGeneration = stored.Generation
+	stored.Status = status
+	Expect(args.client.Status().Update(context.TODO(), stored)).To(Succeed())
+}
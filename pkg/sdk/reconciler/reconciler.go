@@ -17,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -44,21 +45,33 @@ const (
 	updateResourceSuccess = "UpdateResourceSuccess"
 )
 
+// DefaultAnnotationPrefix is the prefix of the annotations recognized by the Reconciler unless configured otherwise
+const DefaultAnnotationPrefix = "lifecycle.kubevirt.io"
+
 // PerishablesSynchronizer is expected to execute perishable resources (i.e. certificates) synchronization if required
 type PerishablesSynchronizer func(cr client.Object, logger logr.Logger) error
 
+// ContextPerishablesSynchronizer is a PerishablesSynchronizer receiving the reconcile context
+type ContextPerishablesSynchronizer func(ctx context.Context, cr client.Object, logger logr.Logger) error
+
 // ControllerConfigUpdater is expected to update controller configuration if required
 type ControllerConfigUpdater func(cr client.Object) error
 
 // SanityChecker is expected to check if it makes sense to execute the reconciliation if required
 type SanityChecker func(cr client.Object, logger logr.Logger) (*reconcile.Result, error)
 
+// ContextSanityChecker is a SanityChecker receiving the reconcile context
+type ContextSanityChecker func(ctx context.Context, cr client.Object, logger logr.Logger) (*reconcile.Result, error)
+
 // WatchRegistrator is expected to register additional resource watchers if required
 type WatchRegistrator func() error
 
 // PreCreateHook is expected to perform custom actions before the creation of the managed resources is initiated
 type PreCreateHook func(cr client.Object) error
 
+// ContextPreCreateHook is a PreCreateHook receiving the reconcile context
+type ContextPreCreateHook func(ctx context.Context, cr client.Object) error
+
 // CrManager defines interface that needs to be provided for the reconciler to operate
 type CrManager interface {
 	// IsCreating checks whether creation of the managed resources will be executed
@@ -73,6 +86,15 @@ type CrManager interface {
 	GetDependantResourcesListObjects() []client.ObjectList
 }
 
+// ContextCrManager may be implemented by a CrManager that needs the reconcile context; its methods are used instead
+// of their CrManager counterparts
+type ContextCrManager interface {
+	// IsCreatingContext checks whether creation of the managed resources will be executed
+	IsCreatingContext(ctx context.Context, cr client.Object) (bool, error)
+	// GetAllResourcesContext provides all resources managed by the cr
+	GetAllResourcesContext(ctx context.Context, cr client.Object) ([]client.Object, error)
+}
+
 // CallbackDispatcher manages and executes resource callbacks
 type CallbackDispatcher interface {
 	// AddCallback registers a callback for given object type
@@ -82,6 +104,13 @@ type CallbackDispatcher interface {
 	InvokeCallbacks(l logr.Logger, cr interface{}, s callbacks.ReconcileState, desiredObj, currentObj client.Object, recorder record.EventRecorder) error
 }
 
+// ContextCallbackDispatcher may be implemented by a CallbackDispatcher that passes the reconcile context to the
+// callbacks; InvokeCallbacksContext is used instead of InvokeCallbacks then
+type ContextCallbackDispatcher interface {
+	// InvokeCallbacksContext executes callbacks for desired/current object type
+	InvokeCallbacksContext(ctx context.Context, l logr.Logger, cr interface{}, s callbacks.ReconcileState, desiredObj, currentObj client.Object, recorder record.EventRecorder) error
+}
+
 // Reconciler is responsible for performing deployment reconciliation
 type Reconciler struct {
 	crManager CrManager
@@ -105,19 +134,51 @@ type Reconciler struct {
 	finalizerName               string
 	namespacedCR                bool
 	subresourceEnabled          bool
+	fieldManager                string
+	annotationPrefix            string
+	readinessCheckers           map[schema.GroupVersionKind]ReadinessChecker
+	healthExpressions           *healthExpressions
+	healthExpressionsByGVK      map[schema.GroupVersionKind]string
+	mergeStrategies             map[schema.GroupVersionKind]MergeStrategy
+	recreatePolicy              RecreatePolicy
+	recreatableKinds            map[schema.GroupVersionKind]bool
+	comparisonMode              ComparisonMode
+	lastAppliedStorage          LastAppliedStorage
+	lastAppliedLock             sync.Mutex
+	pendingLastApplied          sync.Map
+	committedLastApplied        sync.Map
+	relatedObjectsDebounce      time.Duration
+	relatedObjectsWrites        sync.Map
+	resourceStatusLimit         int
+	uncappedResourceStatuses    sync.Map
+	deployDeadline              time.Duration
+	deployDeadlineAction        DeployDeadlineAction
+	upgradeDeadline             time.Duration
+	upgradeDeadlineAction       UpgradeDeadlineAction
+	snapshotNamespace           string
+	downgradePolicy             DowngradePolicy
+	downgradeHooks              []DowngradeHook
+	resourceConcurrency         int
 
 	// Hooks
-	syncPerishables               PerishablesSynchronizer
+	syncPerishables               ContextPerishablesSynchronizer
 	updateControllerConfiguration ControllerConfigUpdater
-	checkSanity                   SanityChecker
+	checkSanity                   ContextSanityChecker
 	watch                         WatchRegistrator
-	preCreate                     PreCreateHook
+	preCreate                     ContextPreCreateHook
 }
 
 // Reconcile performs request reconciliation
+//
+// Deprecated: use ReconcileContext, which receives the context of the reconcile request
 func (r *Reconciler) Reconcile(request reconcile.Request, operatorVersion string, reqLogger logr.Logger) (reconcile.Result, error) {
+	return r.ReconcileContext(context.TODO(), request, operatorVersion, reqLogger)
+}
+
+// ReconcileContext performs request reconciliation
+func (r *Reconciler) ReconcileContext(ctx context.Context, request reconcile.Request, operatorVersion string, reqLogger logr.Logger) (reconcile.Result, error) {
 	// Fetch the CR instance
-	cr, err := r.GetCr(request.NamespacedName)
+	cr, err := r.GetCrContext(ctx, request.NamespacedName)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -127,32 +188,65 @@ func (r *Reconciler) Reconcile(request reconcile.Request, operatorVersion string
 		}
 		return reconcile.Result{}, err
 	}
+	ctx = r.withDesiredResources(ctx, cr)
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	ctx = r.withStatusBatch(ctx, cr)
+
+	res, err := r.reconcileCr(ctx, cr, operatorVersion, reqLogger)
+	res, err = r.handleReconcileError(ctx, reqLogger, cr, res, err)
+	relatedObjectsLeft, flushErr := r.flushRelatedObjects(ctx, cr)
+	if flushErr == nil {
+		flushErr = r.flushStatus(ctx, cr)
+	}
+	if flushErr != nil {
+		return reconcile.Result{}, flushErr
+	}
+	// the debounced related objects are written by a later pass
+	if relatedObjectsLeft > 0 && (res.RequeueAfter == 0 || relatedObjectsLeft < res.RequeueAfter) {
+		res.RequeueAfter = relatedObjectsLeft
+	}
+	return res, err
+}
 
+// reconcileCr reconciles the fetched cr
+func (r *Reconciler) reconcileCr(ctx context.Context, cr client.Object, operatorVersion string, reqLogger logr.Logger) (reconcile.Result, error) {
 	// make sure we're watching eveything
-	if err := r.WatchDependantResources(cr); err != nil {
+	if err := r.WatchDependantResourcesContext(ctx, cr); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	// mid delete
 	if cr.GetDeletionTimestamp() != nil {
 		reqLogger.Info("Doing reconcile delete")
-		return r.ReconcileDelete(reqLogger, cr, r.finalizerName)
+		return r.ReconcileDeleteContext(ctx, reqLogger, cr, r.finalizerName)
+	}
+
+	if r.isPaused(cr) {
+		reqLogger.Info("Reconciliation paused")
+		return r.reconcilePaused(ctx, reqLogger, cr)
+	}
+
+	if r.isPlanOnly(cr) {
+		reqLogger.Info("Doing reconcile plan")
+		return r.reconcilePlanOnly(ctx, reqLogger, cr, operatorVersion)
 	}
 
-	status := r.status(cr)
-	creating, err := r.crManager.IsCreating(cr)
+	status := r.status(ctx, cr)
+	creating, err := r.isCreating(ctx, cr)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	if creating {
-		if status.Phase != "" {
+		// a CR in the Error phase is deployed again
+		if status.Phase != "" && !r.recoverableError(ctx, cr, operatorVersion) {
 			reqLogger.Info("Reconciling to error state, illegal phase", "phase", status.Phase)
 			// we are in a weird state
-			return r.ReconcileError(cr, "Reconciling to error state, illegal phase")
+			return r.ReconcileErrorContext(ctx, cr, "Reconciling to error state, illegal phase")
 		}
 
-		haveOrphans, err := r.CheckForOrphans(reqLogger, cr)
+		haveOrphans, err := r.CheckForOrphansContext(ctx, reqLogger, cr)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
@@ -161,33 +255,46 @@ func (r *Reconciler) Reconcile(request reconcile.Request, operatorVersion string
 			return reconcile.Result{RequeueAfter: time.Second}, nil
 		}
 		reqLogger.Info("Doing reconcile create")
-		if err := r.preCreate(cr); err != nil {
+		if err := r.preCreate(ctx, cr); err != nil {
 			return reconcile.Result{}, err
 		}
 		reqLogger.Info("Pre-create hook executed successfully")
 
-		status := r.crManager.Status(cr)
+		status := r.status(ctx, cr)
 		sdk.MarkCrDeploying(cr, status, "DeployStarted", "Started Deployment", r.recorder)
 
-		if err := r.CrInit(cr, operatorVersion); err != nil {
+		if err := r.CrInitContext(ctx, cr, operatorVersion); err != nil {
 			return reconcile.Result{}, err
 		}
 
 		reqLogger.Info("Successfully entered Deploying state")
+	} else if r.recoverableError(ctx, cr, operatorVersion) {
+		if err := r.recoverFromError(ctx, cr, operatorVersion); err != nil {
+			return reconcile.Result{}, err
+		}
+		reqLogger.Info("Recovering from Error state", "phase", status.Phase)
 	}
 
 	// do we even care about this CR?
-	result, err := r.checkSanity(cr, reqLogger)
+	result, err := r.checkSanity(ctx, cr, reqLogger)
 	if result != nil {
 		return *result, err
 	}
 
 	currentConditionValues := sdk.GetConditionValues(status.Conditions)
+	r.clearPlanned(ctx, cr)
+	r.clearPaused(ctx, cr)
 	reqLogger.Info("Doing reconcile update")
 
-	res, err := r.ReconcileUpdate(reqLogger, cr, operatorVersion)
+	res, err := r.ReconcileUpdateContext(ctx, reqLogger, cr, operatorVersion)
+	if err == nil && status.ObservedGeneration != cr.GetGeneration() {
+		status.ObservedGeneration = cr.GetGeneration()
+		if err := r.CrUpdateStatusContext(ctx, status.Phase, cr); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 	if sdk.ConditionsChanged(currentConditionValues, sdk.GetConditionValues(status.Conditions)) {
-		if err := r.CrUpdateStatus(status.Phase, cr); err != nil {
+		if err := r.CrUpdateStatusContext(ctx, status.Phase, cr); err != nil {
 			return reconcile.Result{}, err
 		}
 	}
@@ -196,8 +303,30 @@ func (r *Reconciler) Reconcile(request reconcile.Request, operatorVersion string
 }
 
 // ReconcileUpdate executes Update operation
+//
+// Deprecated: use ReconcileUpdateContext, which receives the context of the reconcile request
 func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, operatorVersion string) (reconcile.Result, error) {
-	if err := r.CheckUpgrade(logger, cr, operatorVersion); err != nil {
+	return r.ReconcileUpdateContext(context.TODO(), logger, cr, operatorVersion)
+}
+
+// ReconcileUpdateContext executes Update operation
+func (r *Reconciler) ReconcileUpdateContext(ctx context.Context, logger logr.Logger, cr client.Object, operatorVersion string) (reconcile.Result, error) {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	if r.upgradeFailed(ctx, cr, operatorVersion) {
+		logger.Info("Upgrade to this version failed, waiting for another operator version", "version", operatorVersion)
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.CheckUpgradeContext(ctx, logger, cr, operatorVersion); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if handled, err := r.handleUpgradeDeadline(ctx, logger, cr, operatorVersion); handled || err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if handled, err := r.handleDeployDeadline(ctx, logger, cr); handled || err != nil {
 		return reconcile.Result{}, err
 	}
 
@@ -206,164 +335,337 @@ func (r *Reconciler) ReconcileUpdate(logger logr.Logger, cr client.Object, opera
 		return reconcile.Result{}, err
 	}
 
-	resources, err := r.crManager.GetAllResources(cr)
+	waves, err := r.getResourceWaves(ctx, cr)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	var allErrors []error
-	for _, desiredObj := range resources {
-		currentObj := sdk.NewDefaultInstance(desiredObj)
+	var reconciled []client.Object
+	var blockingWave *applyWave
+	var notReady client.Object
+	gateWaves := r.gatesApplyWaves(ctx, cr)
+	for i := range waves {
+		waveErrors, err := r.reconcileResources(ctx, logger, cr, waves[i].resources, operatorVersion)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		allErrors = append(allErrors, waveErrors...)
+		reconciled = append(reconciled, waves[i].resources...)
 
-		key := client.ObjectKey{
-			Namespace: desiredObj.GetNamespace(),
-			Name:      desiredObj.GetName(),
+		if len(allErrors) > 0 || i == len(waves)-1 {
+			break
+		}
+		if !gateWaves {
+			continue
 		}
-		err = r.client.Get(context.TODO(), key, currentObj)
 
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return reconcile.Result{}, err
-			}
+		// the next wave may depend on this one
+		if notReady, err = r.findNotReady(ctx, cr, waves[i]); err != nil {
+			return reconcile.Result{}, err
+		}
+		if notReady != nil {
+			blockingWave = &waves[i]
+			break
+		}
+	}
 
-			r.setLastAppliedConfiguration(desiredObj)
-			sdk.SetLabel(r.createVersionLabel, operatorVersion, desiredObj)
-			r.setRecommendedLabels(cr, desiredObj)
+	if err = r.syncRelatedObjects(ctx, logger, cr, r.appliedResources(reconciled, allErrors)); err != nil {
+		return reconcile.Result{}, err
+	}
 
-			if err = controllerutil.SetControllerReference(cr, desiredObj, r.scheme); err != nil {
-				r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
-				return reconcile.Result{}, err
-			}
+	if err = r.recordResourceSyncs(ctx, cr, reconciled, allErrors); err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// PRE_CREATE callback
-			if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePreCreate, desiredObj, nil, r.recorder); err != nil {
-				r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
-				return reconcile.Result{}, err
-			}
+	if err = r.syncPerishables(ctx, cr, logger); err != nil {
+		return reconcile.Result{}, err
+	}
 
-			currentObj = desiredObj.DeepCopyObject().(client.Object)
-			if err = r.client.Create(context.TODO(), currentObj); err != nil {
-				logger.Error(err, "")
-				allErrors = append(allErrors, err)
-				r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
-				continue
-			}
+	if len(allErrors) > 0 {
+		errs := resourceErrors(allErrors)
+		if err = r.markReconcileFailed(ctx, cr, errs); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, errs
+	}
+	if err = r.clearReconcileFailed(ctx, cr); err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// POST_CREATE callback
-			if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostCreate, desiredObj, nil, r.recorder); err != nil {
-				r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
-				return reconcile.Result{}, err
-			}
+	if blockingWave != nil {
+		if err = r.markWaitingForApplyWave(ctx, logger, cr, *blockingWave, notReady); err != nil {
+			return reconcile.Result{}, err
+		}
+		return r.requeueBeforePhaseDeadline(ctx, cr, reconcile.Result{RequeueAfter: applyWaveRequeueInterval}), nil
+	}
+	r.clearWaitingForApplyWave(ctx, cr)
 
-			logger.Info("Resource created",
-				"namespace", desiredObj.GetNamespace(),
-				"name", desiredObj.GetName(),
-				"type", fmt.Sprintf("%T", desiredObj))
-			r.recorder.Event(cr, corev1.EventTypeNormal, createResourceSuccess, fmt.Sprintf("Successfully created resource %T %s", desiredObj, desiredObj.GetName()))
-		} else {
-			// POST_READ callback
-			if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostRead, desiredObj, currentObj, r.recorder); err != nil {
-				return reconcile.Result{}, err
-			}
+	degraded, err := r.CheckDegradedContext(ctx, logger, cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			currentObj, err = sdk.StripStatusFromObject(currentObj)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
-			currentObjCopy := currentObj.DeepCopyObject().(client.Object)
+	status := r.status(ctx, cr)
+	// a CR failed by the deploy deadline hasn't been deployed by any version
+	if status.Phase != sdkapi.PhaseDeployed && (!sdk.IsUpgrading(status) || r.deployTimedOut(ctx, cr)) && !degraded {
+		//We are not moving to Deployed phase until new operator deployment is ready in case of Upgrade
+		status.ObservedVersion = operatorVersion
+		sdk.MarkCrHealthyMessage(cr, status, "DeployCompleted", "Deployment Completed", r.recorder)
+		if err = r.CrUpdateStatusContext(ctx, sdkapi.PhaseDeployed, cr); err != nil {
+			return reconcile.Result{}, err
+		}
 
-			// allow users to add new annotations (but not change ours)
-			sdk.MergeLabelsAndAnnotations(desiredObj, currentObj)
+		logger.Info("Successfully entered Deployed state")
+	}
 
-			// recommended label values can change by installer, set on update as well
-			r.setRecommendedLabels(cr, currentObj)
+	if !degraded && sdk.IsUpgrading(status) {
+		logger.Info("Completing upgrade process...")
 
-			if !sdk.IsMutable(currentObj) {
-				r.setLastAppliedConfiguration(desiredObj)
+		if err = r.completeUpgrade(ctx, logger, cr, operatorVersion); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 
-				// overwrite currentRuntimeObj
-				currentObj, err = sdk.MergeObject(desiredObj, currentObj, r.lastAppliedConfigAnnotation)
-				if err != nil {
-					return reconcile.Result{}, err
-				}
-			}
+	if !degraded && status.Phase == sdkapi.PhaseDeployed {
+		if err = r.storeSnapshot(ctx, logger, cr); err != nil {
+			// only a later rollback depends on the snapshot, it fails without it
+			logger.Error(err, "Failed to store snapshot of managed resources")
+			r.recorder.Event(cr, corev1.EventTypeWarning, snapshotFailed, fmt.Sprintf("Failed to store snapshot of managed resources: %v", err))
+		}
+	}
 
-			if !reflect.DeepEqual(currentObjCopy, currentObj) {
-				sdk.LogJSONDiff(logger, currentObjCopy, currentObj)
-				sdk.SetLabel(r.updateVersionLabel, operatorVersion, currentObj)
+	return r.requeueBeforePhaseDeadline(ctx, cr, reconcile.Result{RequeueAfter: r.perishablesSyncInterval}), nil
+}
 
-				// PRE_UPDATE callback
-				if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePreUpdate, desiredObj, currentObj, r.recorder); err != nil {
-					r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
-					return reconcile.Result{}, err
-				}
+// reconcileResource creates the desired object or brings the existing one to the desired state. A failed create or
+// update call is reported as ResourceError, which doesn't stop the reconciliation of the remaining resources.
+func (r *Reconciler) reconcileResource(ctx context.Context, logger logr.Logger, cr client.Object, desiredObj client.Object, operatorVersion string) error {
+	currentObj := sdk.NewDefaultInstance(desiredObj)
 
-				if err = r.client.Update(context.TODO(), currentObj); err != nil {
-					logger.Error(err, "")
-					allErrors = append(allErrors, err)
-					r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
-					continue
-				}
+	key := client.ObjectKey{
+		Namespace: desiredObj.GetNamespace(),
+		Name:      desiredObj.GetName(),
+	}
+	err := r.client.Get(ctx, key, currentObj)
 
-				// POST_UPDATE callback
-				if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostUpdate, desiredObj, nil, r.recorder); err != nil {
-					r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
-					return reconcile.Result{}, err
-				}
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
 
-				logger.Info("Resource updated",
-					"namespace", desiredObj.GetNamespace(),
-					"name", desiredObj.GetName(),
-					"type", fmt.Sprintf("%T", desiredObj))
-				r.recorder.Event(cr, corev1.EventTypeNormal, updateResourceSuccess, fmt.Sprintf("Successfully updated resource %T %s", desiredObj, desiredObj.GetName()))
-			} else {
-				logger.V(3).Info("Resource unchanged",
-					"namespace", desiredObj.GetNamespace(),
-					"name", desiredObj.GetName(),
-					"type", fmt.Sprintf("%T", desiredObj))
+		return r.createResource(ctx, logger, cr, desiredObj, operatorVersion)
+	}
+
+	policy, err := r.reconcilePolicy(desiredObj, currentObj)
+	if err != nil {
+		r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
+		return r.resourceError(desiredObj, ResourceOperationUpdate, err)
+	}
+	if policy != ReconcilePolicyEnforce {
+		logger.V(3).Info("Resource not reconciled",
+			"namespace", desiredObj.GetNamespace(),
+			"name", desiredObj.GetName(),
+			"type", fmt.Sprintf("%T", desiredObj),
+			"policy", policy)
+		return nil
+	}
+
+	// POST_READ callback
+	if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePostRead, desiredObj, currentObj, r.recorder); err != nil {
+		return err
+	}
+
+	currentObj, err = sdk.StripStatusFromObject(currentObj)
+	if err != nil {
+		return err
+	}
+	currentObjCopy := currentObj.DeepCopyObject().(client.Object)
+	// updatedState modifies desiredObj, a recreated object starts from the unmodified one
+	pristineObj := desiredObj.DeepCopyObject().(client.Object)
+
+	var applyObj client.Object
+	currentObj, applyObj, err = r.updatedState(ctx, cr, desiredObj, currentObj, operatorVersion)
+	if err != nil {
+		return err
+	}
+
+	unchanged, normalised, err := r.compareStates(ctx, currentObjCopy, currentObj)
+	if err != nil {
+		return err
+	}
+
+	if !unchanged {
+		sdk.LogJSONDiff(logger, currentObjCopy, currentObj)
+		sdk.SetLabel(r.updateVersionLabel, operatorVersion, currentObj)
+
+		// PRE_UPDATE callback
+		var callbackBase client.Object
+		if applyObj != nil {
+			callbackBase = currentObj.DeepCopyObject().(client.Object)
+		}
+		if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePreUpdate, desiredObj, currentObj, r.recorder); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
+			return err
+		}
+
+		if applyObj != nil {
+			if applyObj, err = withCallbackChanges(applyObj, callbackBase, currentObj); err != nil {
+				return err
 			}
+			sdk.SetLabel(r.updateVersionLabel, operatorVersion, applyObj)
+			err = r.apply(ctx, applyObj)
+		} else {
+			err = r.client.Update(ctx, currentObj)
+		}
+		if err != nil && r.shouldRecreate(currentObj, err) {
+			return r.recreateResource(ctx, logger, cr, pristineObj, currentObj, operatorVersion)
 		}
+		if err != nil {
+			logger.Error(err, "")
+			r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
+			return r.resourceError(desiredObj, ResourceOperationUpdate, err)
+		}
+
+		// POST_UPDATE callback
+		if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePostUpdate, desiredObj, nil, r.recorder); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, updateResourceFailed, fmt.Sprintf("Failed to update resource %s, %v", desiredObj.GetName(), err))
+			return err
+		}
+
+		logger.Info("Resource updated",
+			"namespace", desiredObj.GetNamespace(),
+			"name", desiredObj.GetName(),
+			"type", fmt.Sprintf("%T", desiredObj))
+		r.recorder.Event(cr, corev1.EventTypeNormal, updateResourceSuccess, fmt.Sprintf("Successfully updated resource %T %s", desiredObj, desiredObj.GetName()))
+	} else {
+		if normalised {
+			avoidedUpdates.WithLabelValues(string(r.comparisonMode)).Inc()
+		}
+		logger.V(3).Info("Resource unchanged",
+			"namespace", desiredObj.GetNamespace(),
+			"name", desiredObj.GetName(),
+			"type", fmt.Sprintf("%T", desiredObj))
 	}
 
-	if err = r.syncPerishables(cr, logger); err != nil {
-		return reconcile.Result{}, err
+	// the live object carries the saved configuration now, also when a previous pass failed to record it
+	return r.commitLastApplied(ctx, cr, desiredObj)
+}
+
+// createResource creates desiredObj with the labels, annotations and owner reference of the managed resources
+func (r *Reconciler) createResource(ctx context.Context, logger logr.Logger, cr client.Object, desiredObj client.Object, operatorVersion string) error {
+	err := r.prepareForCreate(ctx, cr, desiredObj, operatorVersion)
+	if err != nil {
+		r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
+		return err
 	}
 
-	if len(allErrors) > 0 {
-		return reconcile.Result{}, fmt.Errorf("reconcile encountered %d errors", len(allErrors))
+	// PRE_CREATE callback
+	if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePreCreate, desiredObj, nil, r.recorder); err != nil {
+		r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
+		return err
 	}
 
-	degraded, err := r.CheckDegraded(logger, cr)
+	currentObj := desiredObj.DeepCopyObject().(client.Object)
+	if r.useServerSideApply() {
+		currentObj, err = r.newApplyObject(cr, desiredObj, nil, operatorVersion)
+		if err == nil {
+			err = r.apply(ctx, currentObj)
+		}
+	} else {
+		err = r.client.Create(ctx, currentObj)
+	}
 	if err != nil {
-		return reconcile.Result{}, err
+		logger.Error(err, "")
+		r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
+		return r.resourceError(desiredObj, ResourceOperationCreate, err)
+	}
+	if err = r.commitLastApplied(ctx, cr, desiredObj); err != nil {
+		return err
 	}
 
-	status := r.status(cr)
-	if status.Phase != sdkapi.PhaseDeployed && !sdk.IsUpgrading(status) && !degraded {
-		//We are not moving to Deployed phase until new operator deployment is ready in case of Upgrade
-		status.ObservedVersion = operatorVersion
-		sdk.MarkCrHealthyMessage(cr, status, "DeployCompleted", "Deployment Completed", r.recorder)
-		if err = r.CrUpdateStatus(sdkapi.PhaseDeployed, cr); err != nil {
-			return reconcile.Result{}, err
-		}
+	// POST_CREATE callback
+	if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePostCreate, desiredObj, nil, r.recorder); err != nil {
+		r.recorder.Event(cr, corev1.EventTypeWarning, createResourceFailed, fmt.Sprintf("Failed to create resource %s, %v", desiredObj.GetName(), err))
+		return err
+	}
 
-		logger.Info("Successfully entered Deployed state")
+	logger.Info("Resource created",
+		"namespace", desiredObj.GetNamespace(),
+		"name", desiredObj.GetName(),
+		"type", fmt.Sprintf("%T", desiredObj))
+	r.recorder.Event(cr, corev1.EventTypeNormal, createResourceSuccess, fmt.Sprintf("Successfully created resource %T %s", desiredObj, desiredObj.GetName()))
+	return nil
+}
+
+// prepareForCreate sets the labels, annotations and owner reference desiredObj is created with
+func (r *Reconciler) prepareForCreate(ctx context.Context, cr client.Object, desiredObj client.Object, operatorVersion string) error {
+	if err := r.setLastAppliedConfiguration(ctx, cr, desiredObj); err != nil {
+		return err
 	}
+	sdk.SetLabel(r.createVersionLabel, operatorVersion, desiredObj)
+	r.setRecommendedLabels(cr, desiredObj)
 
-	if !degraded && sdk.IsUpgrading(status) {
-		logger.Info("Completing upgrade process...")
+	return controllerutil.SetControllerReference(cr, desiredObj, r.scheme)
+}
 
-		if err = r.completeUpgrade(logger, cr, operatorVersion); err != nil {
-			return reconcile.Result{}, err
+// updatedState computes the state currentObj (with stripped status) has to be brought to. In the server-side apply
+// mode the configuration to apply is returned as well. The ignored fields keep their current values.
+func (r *Reconciler) updatedState(ctx context.Context, cr client.Object, desiredObj, currentObj client.Object, operatorVersion string) (client.Object, client.Object, error) {
+	ignoredFields, err := r.ignoredFields(desiredObj, currentObj)
+	if err != nil {
+		return nil, nil, err
+	}
+	originalObj := currentObj.DeepCopyObject().(client.Object)
+
+	if r.useServerSideApply() {
+		applyObj, err := r.newApplyObject(cr, desiredObj, currentObj, operatorVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ignoredFields) > 0 {
+			if applyObj, err = keepFields(applyObj, originalObj, ignoredFields); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// the outcome of the apply is what the object is compared against
+		updatedObj, err := r.dryRunApply(ctx, applyObj, currentObj)
+		if err != nil {
+			return nil, nil, err
 		}
+		return updatedObj, applyObj, nil
 	}
 
-	return reconcile.Result{RequeueAfter: r.perishablesSyncInterval}, nil
+	// allow users to add new annotations (but not change ours)
+	sdk.MergeLabelsAndAnnotations(desiredObj, currentObj)
+
+	// recommended label values can change by installer, set on update as well
+	r.setRecommendedLabels(cr, currentObj)
+
+	updatedObj, err := r.mergeObject(ctx, cr, desiredObj, currentObj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(ignoredFields) > 0 {
+		if updatedObj, err = keepFields(updatedObj, originalObj, ignoredFields); err != nil {
+			return nil, nil, err
+		}
+	}
+	return updatedObj, nil, nil
 }
 
 // CheckForOrphans checks whether there are any orphaned resources (ones that exist in the cluster but shouldn't)
+//
+// Deprecated: use CheckForOrphansContext, which receives the context of the reconcile request
 func (r *Reconciler) CheckForOrphans(logger logr.Logger, cr client.Object) (bool, error) {
-	resources, err := r.crManager.GetAllResources(cr)
+	return r.CheckForOrphansContext(context.TODO(), logger, cr)
+}
+
+// CheckForOrphansContext checks whether there are any orphaned resources (ones that exist in the cluster but shouldn't)
+func (r *Reconciler) CheckForOrphansContext(ctx context.Context, logger logr.Logger, cr client.Object) (bool, error) {
+	resources, err := r.getAllResources(ctx, cr)
 	if err != nil {
 		return false, err
 	}
@@ -372,7 +674,7 @@ func (r *Reconciler) CheckForOrphans(logger logr.Logger, cr client.Object) (bool
 		cpy := resource.DeepCopyObject().(client.Object)
 		key := client.ObjectKeyFromObject(cpy)
 
-		if err = r.client.Get(context.TODO(), key, cpy); err != nil {
+		if err = r.client.Get(ctx, key, cpy); err != nil {
 			if errors.IsNotFound(err) {
 				continue
 			}
@@ -387,45 +689,116 @@ func (r *Reconciler) CheckForOrphans(logger logr.Logger, cr client.Object) (bool
 	return false, nil
 }
 
-// CrUpdate sets given phase on the CR and updates it in the cluster
+// CrUpdate writes the changes of the CR but its status, e.g. its finalizers, with a merge patch. During a reconcile
+// pass, status changes deferred by CrUpdateStatus are written along unless the status subresource is enabled; outside
+// of it, writing a stale copy fails with a conflict.
+//
+// Deprecated: use CrUpdateContext, which receives the context of the reconcile request
 func (r *Reconciler) CrUpdate(cr client.Object) error {
-	return r.client.Update(context.TODO(), cr)
+	return r.CrUpdateContext(context.TODO(), cr)
 }
 
-// CrUpdateStatus sets given phase on the CR and updates it in the cluster
+// CrUpdateContext writes the changes of the CR but its status, e.g. its finalizers, with a merge patch. During a reconcile
+// pass, status changes deferred by CrUpdateStatus are written along unless the status subresource is enabled; outside
+// of it, writing a stale copy fails with a conflict.
+func (r *Reconciler) CrUpdateContext(ctx context.Context, cr client.Object) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	batch := r.statusBatch(ctx, cr)
+	if batch == nil {
+		return r.patchCrLocked(ctx, cr, false)
+	}
+
+	var pending sdkapi.Status
+	r.status(ctx, cr).DeepCopyInto(&pending)
+	if err := r.patchCr(ctx, cr, batch.written, false); err != nil {
+		return err
+	}
+	batch.written = cr.DeepCopyObject().(client.Object)
+	if r.subresourceEnabled {
+		// the status in the cluster is left as is, the deferred one is restored
+		*r.status(ctx, cr) = pending
+	} else {
+		batch.pending = false
+	}
+	return nil
+}
+
+// CrUpdateStatus sets given phase on the CR and writes its status with a merge patch. During a reconcile pass, the
+// status is written once at its end; outside of it, the status of a stale copy is applied to the current CR.
+//
+// Deprecated: use CrUpdateStatusContext, which receives the context of the reconcile request
 func (r *Reconciler) CrUpdateStatus(phase sdkapi.Phase, cr client.Object) error {
-	status := r.crManager.Status(cr)
+	return r.CrUpdateStatusContext(context.TODO(), phase, cr)
+}
+
+// CrUpdateStatusContext sets given phase on the CR and writes its status with a merge patch. During a reconcile pass, the
+// status is written once at its end; outside of it, the status of a stale copy is applied to the current CR.
+func (r *Reconciler) CrUpdateStatusContext(ctx context.Context, phase sdkapi.Phase, cr client.Object) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	status := r.status(ctx, cr)
+	if status.Phase != phase {
+		now := metav1.Now()
+		status.PhaseTransitionTime = &now
+	}
 	status.Phase = phase
-	if r.subresourceEnabled {
-		return r.client.Status().Update(context.TODO(), cr)
+	if batch := r.statusBatch(ctx, cr); batch != nil {
+		batch.pending = true
+		return nil
 	}
-	return r.CrUpdate(cr)
+	return r.patchCrLocked(ctx, cr, true)
 }
 
 // CrSetVersion sets version and phase on the CR object
+//
+// Deprecated: use CrSetVersionContext, which receives the context of the reconcile request
 func (r *Reconciler) CrSetVersion(cr client.Object, version string) error {
+	return r.CrSetVersionContext(context.TODO(), cr, version)
+}
+
+// CrSetVersionContext sets version and phase on the CR object
+func (r *Reconciler) CrSetVersionContext(ctx context.Context, cr client.Object, version string) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
 	phase := sdkapi.PhaseDeployed
 	if version == "" {
 		phase = sdkapi.PhaseEmpty
 	}
-	status := r.status(cr)
+	status := r.status(ctx, cr)
 	status.ObservedVersion = version
 	status.OperatorVersion = version
 	status.TargetVersion = version
-	return r.CrUpdateStatus(phase, cr)
+	return r.CrUpdateStatusContext(ctx, phase, cr)
 }
 
 // CrError sets the CR's phase to "Error"
+//
+// Deprecated: use CrErrorContext, which receives the context of the reconcile request
 func (r *Reconciler) CrError(cr client.Object) error {
-	status := r.status(cr)
+	return r.CrErrorContext(context.TODO(), cr)
+}
+
+// CrErrorContext sets the CR's phase to "Error"
+func (r *Reconciler) CrErrorContext(ctx context.Context, cr client.Object) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	status := r.status(ctx, cr)
 	if status.Phase != sdkapi.PhaseError {
-		return r.CrUpdateStatus(sdkapi.PhaseError, cr)
+		return r.CrUpdateStatusContext(ctx, sdkapi.PhaseError, cr)
 	}
 	return nil
 }
 
 // WatchDependantResources registers watches for dependant resource types
+//
+// Deprecated: use WatchDependantResourcesContext, which receives the context of the reconcile request
 func (r *Reconciler) WatchDependantResources(cr client.Object) error {
+	return r.WatchDependantResourcesContext(context.TODO(), cr)
+}
+
+// WatchDependantResourcesContext registers watches for dependant resource types
+func (r *Reconciler) WatchDependantResourcesContext(ctx context.Context, cr client.Object) error {
 	r.watchMutex.Lock()
 	defer r.watchMutex.Unlock()
 
@@ -433,7 +806,7 @@ func (r *Reconciler) WatchDependantResources(cr client.Object) error {
 		return nil
 	}
 
-	resources, err := r.crManager.GetAllResources(cr)
+	resources, err := r.getAllResources(ctx, cr)
 	if err != nil {
 		return err
 	}
@@ -452,13 +825,22 @@ func (r *Reconciler) WatchDependantResources(cr client.Object) error {
 }
 
 // ReconcileError Marks CR as failed
+//
+// Deprecated: use ReconcileErrorContext, which receives the context of the reconcile request
 func (r *Reconciler) ReconcileError(cr client.Object, message string) (reconcile.Result, error) {
-	status := r.status(cr)
+	return r.ReconcileErrorContext(context.TODO(), cr, message)
+}
+
+// ReconcileErrorContext Marks CR as failed
+func (r *Reconciler) ReconcileErrorContext(ctx context.Context, cr client.Object, message string) (reconcile.Result, error) {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	status := r.status(ctx, cr)
 	sdk.MarkCrFailed(cr, status, "ConfigError", message, r.recorder)
-	if err := r.CrUpdateStatus(status.Phase, cr); err != nil {
+	if err := r.CrUpdateStatusContext(ctx, status.Phase, cr); err != nil {
 		return reconcile.Result{}, err
 	}
-	if err := r.CrError(cr); err != nil {
+	if err := r.CrErrorContext(ctx, cr); err != nil {
 		return reconcile.Result{}, err
 	}
 
@@ -466,36 +848,59 @@ func (r *Reconciler) ReconcileError(cr client.Object, message string) (reconcile
 }
 
 // CheckDegraded checks whether the deployment is degraded and updates CR status conditions accordingly
+//
+// Deprecated: use CheckDegradedContext, which receives the context of the reconcile request
 func (r *Reconciler) CheckDegraded(logger logr.Logger, cr client.Object) (bool, error) {
+	return r.CheckDegradedContext(context.TODO(), logger, cr)
+}
+
+// CheckDegradedContext checks whether the deployment is degraded and updates CR status conditions accordingly
+func (r *Reconciler) CheckDegradedContext(ctx context.Context, logger logr.Logger, cr client.Object) (bool, error) {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
 	degraded := false
 
-	deployments, err := r.GetAllDeployments(cr)
+	resources, err := r.getAllResources(ctx, cr)
 	if err != nil {
 		return true, err
 	}
 
-	for _, deployment := range deployments {
-		key := client.ObjectKey{Namespace: deployment.Namespace, Name: deployment.Name}
-
-		if err = r.client.Get(context.TODO(), key, deployment); err != nil {
+	readiness := map[corev1.ObjectReference]bool{}
+	for _, resource := range resources {
+		ready, err := r.isResourceReady(ctx, cr, resource)
+		if err != nil {
 			return true, err
 		}
+		readiness[r.objectReference(resource)] = ready
 
-		if !sdk.CheckDeploymentReady(deployment) {
+		if !ready {
+			logger.Info("Resource not ready",
+				"namespace", resource.GetNamespace(),
+				"name", resource.GetName(),
+				"type", fmt.Sprintf("%T", resource))
 			degraded = true
-			break
+			// the resource status reports the readiness of all the resources
+			if !r.resourceStatusEnabled() {
+				break
+			}
 		}
 	}
 
+	if err := r.recordResourceReadiness(ctx, cr, readiness); err != nil {
+		return true, err
+	}
+
 	logger.Info("Degraded check", "Degraded", degraded)
 
 	// If deployed and degraded, mark degraded, otherwise we are still deploying or not degraded.
-	status := r.status(cr)
+	status := r.status(ctx, cr)
 	if degraded && status.Phase == sdkapi.PhaseDeployed {
 		conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
 			Type:   conditions.ConditionDegraded,
 			Status: corev1.ConditionTrue,
 		})
+	} else if timeout := r.timeoutCondition(ctx, cr); timeout != nil {
+		conditions.SetStatusCondition(&status.Conditions, *timeout)
 	} else {
 		conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
 			Type:   conditions.ConditionDegraded,
@@ -508,14 +913,21 @@ func (r *Reconciler) CheckDegraded(logger logr.Logger, cr client.Object) (bool,
 }
 
 // InvokeDeleteCallbacks executes operator deletion callbacks
+//
+// Deprecated: use InvokeDeleteCallbacksContext, which receives the context of the reconcile request
 func (r *Reconciler) InvokeDeleteCallbacks(logger logr.Logger, cr client.Object) error {
-	desiredResources, err := r.crManager.GetAllResources(cr)
+	return r.InvokeDeleteCallbacksContext(context.TODO(), logger, cr)
+}
+
+// InvokeDeleteCallbacksContext executes operator deletion callbacks
+func (r *Reconciler) InvokeDeleteCallbacksContext(ctx context.Context, logger logr.Logger, cr client.Object) error {
+	desiredResources, err := r.getAllResources(ctx, cr)
 	if err != nil {
 		return err
 	}
 
 	for _, desiredObj := range desiredResources {
-		if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStateOperatorDelete, desiredObj, nil, r.recorder); err != nil {
+		if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStateOperatorDelete, desiredObj, nil, r.recorder); err != nil {
 			return err
 		}
 	}
@@ -524,10 +936,17 @@ func (r *Reconciler) InvokeDeleteCallbacks(logger logr.Logger, cr client.Object)
 }
 
 // GetAllDeployments retrieves all deployments associated to the given CR object
+//
+// Deprecated: use GetAllDeploymentsContext, which receives the context of the reconcile request
 func (r *Reconciler) GetAllDeployments(cr client.Object) ([]*appsv1.Deployment, error) {
+	return r.GetAllDeploymentsContext(context.TODO(), cr)
+}
+
+// GetAllDeploymentsContext retrieves all deployments associated to the given CR object
+func (r *Reconciler) GetAllDeploymentsContext(ctx context.Context, cr client.Object) ([]*appsv1.Deployment, error) {
 	var result []*appsv1.Deployment
 
-	resources, err := r.crManager.GetAllResources(cr)
+	resources, err := r.getAllResources(ctx, cr)
 	if err != nil {
 		return nil, err
 	}
@@ -548,7 +967,17 @@ func (r *Reconciler) WatchCR() error {
 }
 
 // InvokeCallbacks executes callbacks registered
+//
+// Deprecated: use InvokeCallbacksContext, which receives the context of the reconcile request
 func (r *Reconciler) InvokeCallbacks(l logr.Logger, cr client.Object, s callbacks.ReconcileState, desiredObj, currentObj client.Object, recorder record.EventRecorder) error {
+	return r.InvokeCallbacksContext(context.TODO(), l, cr, s, desiredObj, currentObj, recorder)
+}
+
+// InvokeCallbacksContext executes callbacks registered
+func (r *Reconciler) InvokeCallbacksContext(ctx context.Context, l logr.Logger, cr client.Object, s callbacks.ReconcileState, desiredObj, currentObj client.Object, recorder record.EventRecorder) error {
+	if cd, ok := r.callbackDispatcher.(ContextCallbackDispatcher); ok {
+		return cd.InvokeCallbacksContext(ctx, l, cr, s, desiredObj, currentObj, recorder)
+	}
 	return r.callbackDispatcher.InvokeCallbacks(l, cr, s, desiredObj, currentObj, recorder)
 }
 
@@ -588,29 +1017,51 @@ func (r *Reconciler) AddCallback(obj client.Object, cb callbacks.ReconcileCallba
 }
 
 // CheckUpgrade checks whether an upgrade should be performed
+//
+// Deprecated: use CheckUpgradeContext, which receives the context of the reconcile request
 func (r *Reconciler) CheckUpgrade(logger logr.Logger, cr client.Object, targetVersion string) error {
+	return r.CheckUpgradeContext(context.TODO(), logger, cr, targetVersion)
+}
+
+// CheckUpgradeContext checks whether an upgrade should be performed
+func (r *Reconciler) CheckUpgradeContext(ctx context.Context, logger logr.Logger, cr client.Object, targetVersion string) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
 	// should maybe put this in separate function
-	status := r.status(cr)
+	status := r.status(ctx, cr)
 	if status.OperatorVersion != targetVersion {
 		status.OperatorVersion = targetVersion
 		status.TargetVersion = targetVersion
-		if err := r.CrUpdateStatus(status.Phase, cr); err != nil {
+		if err := r.CrUpdateStatusContext(ctx, status.Phase, cr); err != nil {
 			return err
 		}
 	}
 
-	deploying := status.Phase == sdkapi.PhaseDeploying
-	isUpgrade, err := ShouldTakeUpdatePath(targetVersion, status.ObservedVersion, deploying)
+	deploying := status.Phase == sdkapi.PhaseDeploying || r.deployTimedOut(ctx, cr)
+	isUpgrade, isDowngrade, err := ShouldTakeUpdatePathWithPolicy(targetVersion, status.ObservedVersion, deploying, r.downgradePolicy)
 	if err != nil {
 		logger.Error(err, "", "current", status.ObservedVersion, "target", targetVersion)
+		if downgradeErr, ok := err.(*DowngradeError); ok && r.markDowngradeRefused(ctx, cr, downgradeErr) {
+			if updateErr := r.CrUpdateStatusContext(ctx, status.Phase, cr); updateErr != nil {
+				return updateErr
+			}
+		}
 		return err
 	}
 
 	if isUpgrade && status.Phase != sdkapi.PhaseUpgrading {
-		logger.Info("Observed version is not target version. Begin upgrade", "Observed version ", status.ObservedVersion, "TargetVersion", targetVersion)
-		sdk.MarkCrUpgradeHealingDegraded(cr, status, "UpgradeStarted", fmt.Sprintf("Started upgrade to version %s", targetVersion), r.recorder)
+		if isDowngrade {
+			logger.Info("Observed version is newer than target version. Begin downgrade", "Observed version ", status.ObservedVersion, "TargetVersion", targetVersion, "policy", r.downgradePolicy)
+			if err := r.runDowngradeHooks(ctx, cr, status.ObservedVersion, targetVersion); err != nil {
+				return err
+			}
+			sdk.MarkCrUpgradeHealingDegraded(cr, status, downgradeStarted, fmt.Sprintf("Started downgrade from version %s to %s, downgrade policy %s", status.ObservedVersion, targetVersion, r.downgradePolicy), r.recorder)
+		} else {
+			logger.Info("Observed version is not target version. Begin upgrade", "Observed version ", status.ObservedVersion, "TargetVersion", targetVersion)
+			sdk.MarkCrUpgradeHealingDegraded(cr, status, "UpgradeStarted", fmt.Sprintf("Started upgrade to version %s", targetVersion), r.recorder)
+		}
 		status.TargetVersion = targetVersion
-		if err := r.CrUpdateStatus(sdkapi.PhaseUpgrading, cr); err != nil {
+		if err := r.CrUpdateStatusContext(ctx, sdkapi.PhaseUpgrading, cr); err != nil {
 			return err
 		}
 	}
@@ -619,29 +1070,94 @@ func (r *Reconciler) CheckUpgrade(logger logr.Logger, cr client.Object, targetVe
 }
 
 // CleanupUnusedResources removes unused resources
+//
+// Deprecated: use CleanupUnusedResourcesContext, which receives the context of the reconcile request
 func (r *Reconciler) CleanupUnusedResources(logger logr.Logger, cr client.Object) error {
+	return r.CleanupUnusedResourcesContext(context.TODO(), logger, cr)
+}
+
+// CleanupUnusedResourcesContext removes unused resources
+func (r *Reconciler) CleanupUnusedResourcesContext(ctx context.Context, logger logr.Logger, cr client.Object) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
 	//Iterate over installed resources of
 	//Deployment/CRDs/Services etc and delete all resources that
 	//do not exist in current version
 
-	desiredResources, err := r.crManager.GetAllResources(cr)
+	unusedResources, err := r.getUnusedResources(ctx, logger, cr)
 	if err != nil {
 		return err
 	}
 
+	status := r.status(ctx, cr)
+	pruned := false
+	for _, observedObj := range unusedResources {
+		//Invoke pre delete callback
+		if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePreDelete, nil, observedObj, r.recorder); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedObj.GetName(), err))
+			return err
+		}
+
+		logger.Info("Deleting  ", "type", reflect.TypeOf(observedObj), "Name", observedObj.GetName())
+		err = r.client.Delete(ctx, observedObj, &client.DeleteOptions{
+			PropagationPolicy: &[]metav1.DeletionPropagation{metav1.DeletePropagationForeground}[0],
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedObj.GetName(), err))
+			return err
+		}
+
+		//invoke post delete callback
+		if err = r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePostDelete, nil, observedObj, r.recorder); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedObj.GetName(), err))
+			return err
+		}
+		r.recorder.Event(cr, corev1.EventTypeNormal, deleteResourceSuccess, fmt.Sprintf("Successfully deleted resource %T %s", observedObj, observedObj.GetName()))
+
+		removed, err := r.removeRelatedObject(ctx, cr, observedObj)
+		if err != nil {
+			return err
+		}
+		pruned = pruned || removed
+	}
+
+	if err = r.pruneLastApplied(ctx, cr); err != nil {
+		return err
+	}
+	if pruned {
+		return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+	}
+	return nil
+}
+
+// getUnusedResources lists the resources controlled by the cr that the current version doesn't manage anymore
+func (r *Reconciler) getUnusedResources(ctx context.Context, logger logr.Logger, cr client.Object) ([]client.Object, error) {
+	unusedResources, _, err := r.getUndesiredResources(ctx, logger, cr)
+	return unusedResources, err
+}
+
+// getUndesiredResources lists the resources controlled by the cr that the current version doesn't manage anymore,
+// split into the unused ones to be deleted and the ones retained by their reconcile policy
+func (r *Reconciler) getUndesiredResources(ctx context.Context, logger logr.Logger, cr client.Object) ([]client.Object, []client.Object, error) {
+	desiredResources, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	listTypes := r.crManager.GetDependantResourcesListObjects()
 
 	ls, err := labels.Parse(r.createVersionLabel)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
+	var unusedResources, retainedResources []client.Object
 	for _, lt := range listTypes {
 		lo := &client.ListOptions{LabelSelector: ls}
 
-		if err := r.client.List(context.TODO(), lt, lo); err != nil {
+		if err := r.client.List(ctx, lt, lo); err != nil {
 			logger.Error(err, "Error listing resources")
-			return err
+			return nil, nil, err
 		}
 
 		sv := reflect.ValueOf(lt).Elem()
@@ -650,7 +1166,6 @@ func (r *Reconciler) CleanupUnusedResources(logger logr.Logger, cr client.Object
 		for i := 0; i < iv.Len(); i++ {
 			found := false
 			observedObj := iv.Index(i).Addr().Interface().(client.Object)
-			observedMetaObj := observedObj.(metav1.Object)
 
 			for _, desiredObj := range desiredResources {
 				if sdk.SameResource(observedObj, desiredObj) {
@@ -659,37 +1174,31 @@ func (r *Reconciler) CleanupUnusedResources(logger logr.Logger, cr client.Object
 				}
 			}
 
-			if !found && metav1.IsControlledBy(observedMetaObj, cr) {
-				//Invoke pre delete callback
-				if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePreDelete, nil, observedObj, r.recorder); err != nil {
-					r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
-					return err
-				}
-
-				logger.Info("Deleting  ", "type", reflect.TypeOf(observedObj), "Name", observedMetaObj.GetName())
-				err = r.client.Delete(context.TODO(), observedObj, &client.DeleteOptions{
-					PropagationPolicy: &[]metav1.DeletionPropagation{metav1.DeletePropagationForeground}[0],
-				})
-				if err != nil && !errors.IsNotFound(err) {
-					r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
-					return err
-				}
-
-				//invoke post delete callback
-				if err = r.InvokeCallbacks(logger, cr, callbacks.ReconcileStatePostDelete, nil, observedObj, r.recorder); err != nil {
-					r.recorder.Event(cr, corev1.EventTypeWarning, deleteResourceFailed, fmt.Sprintf("Failed deleting resource %s, %v", observedMetaObj.GetName(), err))
-					return err
-				}
-				r.recorder.Event(cr, corev1.EventTypeNormal, deleteResourceSuccess, fmt.Sprintf("Successfully deleted resource %T %s", observedMetaObj, observedMetaObj.GetName()))
+			if found || !metav1.IsControlledBy(observedObj, cr) {
+				continue
+			}
+			if r.isRetained(observedObj) {
+				retainedResources = append(retainedResources, observedObj)
+			} else {
+				unusedResources = append(unusedResources, observedObj)
 			}
 		}
 	}
 
-	return nil
+	return unusedResources, retainedResources, nil
 }
 
 // ReconcileDelete executes Delete operation
+//
+// Deprecated: use ReconcileDeleteContext, which receives the context of the reconcile request
 func (r *Reconciler) ReconcileDelete(logger logr.Logger, cr client.Object, finalizerName string) (reconcile.Result, error) {
+	return r.ReconcileDeleteContext(context.TODO(), logger, cr, finalizerName)
+}
+
+// ReconcileDeleteContext executes Delete operation
+func (r *Reconciler) ReconcileDeleteContext(ctx context.Context, logger logr.Logger, cr client.Object, finalizerName string) (reconcile.Result, error) {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
 	i := -1
 	finalizers := cr.GetFinalizers()
 	for j, f := range finalizers {
@@ -703,26 +1212,33 @@ func (r *Reconciler) ReconcileDelete(logger logr.Logger, cr client.Object, final
 		return reconcile.Result{}, nil
 	}
 
-	status := r.status(cr)
+	status := r.status(ctx, cr)
 	if status.Phase != sdkapi.PhaseDeleting {
-		if err := r.CrUpdateStatus(sdkapi.PhaseDeleting, cr); err != nil {
+		if err := r.CrUpdateStatusContext(ctx, sdkapi.PhaseDeleting, cr); err != nil {
 			return reconcile.Result{}, err
 		}
 	}
 
-	if err := r.InvokeDeleteCallbacks(logger, cr); err != nil {
+	if err := r.InvokeDeleteCallbacksContext(ctx, logger, cr); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := r.CrUpdateStatus(sdkapi.PhaseDeleted, cr); err != nil {
+	if err := r.CrUpdateStatusContext(ctx, sdkapi.PhaseDeleted, cr); err != nil {
+		return reconcile.Result{}, err
+	}
+	// the status can't be written once the CR is gone
+	if err := r.flushStatus(ctx, cr); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	finalizers = append(finalizers[0:i], finalizers[i+1:]...)
 	cr.SetFinalizers(finalizers)
-	if err := r.CrUpdate(cr); err != nil {
+	if err := r.CrUpdateContext(ctx, cr); err != nil {
 		return reconcile.Result{}, err
 	}
+	r.relatedObjectsWrites.Delete(cr.GetUID())
+	r.uncappedResourceStatuses.Delete(cr.GetUID())
+	r.dropPendingLastApplied(cr)
 
 	logger.Info("Finalizer complete")
 
@@ -730,11 +1246,20 @@ func (r *Reconciler) ReconcileDelete(logger logr.Logger, cr client.Object, final
 }
 
 // CrInit initializes the CR and moves it to CR to  "Deploying" status
+//
+// Deprecated: use CrInitContext, which receives the context of the reconcile request
 func (r *Reconciler) CrInit(cr client.Object, operatorVersion string) error {
-	status := r.status(cr)
+	return r.CrInitContext(context.TODO(), cr, operatorVersion)
+}
+
+// CrInitContext initializes the CR and moves it to CR to  "Deploying" status
+func (r *Reconciler) CrInitContext(ctx context.Context, cr client.Object, operatorVersion string) error {
+	ctx, syncView := r.withStatusView(ctx, cr)
+	defer syncView()
+	status := r.status(ctx, cr)
 	status.OperatorVersion = operatorVersion
 	status.TargetVersion = operatorVersion
-	if err := r.CrUpdateStatus(sdkapi.PhaseDeploying, cr); err != nil {
+	if err := r.CrUpdateStatusContext(ctx, sdkapi.PhaseDeploying, cr); err != nil {
 		return err
 	}
 
@@ -746,11 +1271,18 @@ func (r *Reconciler) CrInit(cr client.Object, operatorVersion string) error {
 
 	finalizers := append(cr.GetFinalizers(), r.finalizerName)
 	cr.SetFinalizers(finalizers)
-	return r.CrUpdate(cr)
+	return r.CrUpdateContext(ctx, cr)
 }
 
 // GetCr retrieves the CR
+//
+// Deprecated: use GetCrContext, which receives the context of the reconcile request
 func (r *Reconciler) GetCr(name types.NamespacedName) (client.Object, error) {
+	return r.GetCrContext(context.TODO(), name)
+}
+
+// GetCrContext retrieves the CR
+func (r *Reconciler) GetCrContext(ctx context.Context, name types.NamespacedName) (client.Object, error) {
 	cr := r.crManager.Create()
 	var crKey client.ObjectKey
 	if r.namespacedCR {
@@ -759,29 +1291,63 @@ func (r *Reconciler) GetCr(name types.NamespacedName) (client.Object, error) {
 		// check at cluster level
 		crKey = client.ObjectKey{Namespace: "", Name: name.Name}
 	}
-	err := r.client.Get(context.TODO(), crKey, cr)
+	err := r.client.Get(ctx, crKey, cr)
 	return cr, err
 }
 
-func (r *Reconciler) status(object client.Object) *sdkapi.Status {
+// status returns the status of the cr, the Status view of its MetaStatus carried by ctx if it has one
+func (r *Reconciler) status(ctx context.Context, cr client.Object) *sdkapi.Status {
+	if view := r.statusView(ctx, cr); view != nil {
+		return view
+	}
+	return r.storedStatus(cr)
+}
+
+// storedStatus returns the status of an object other than the reconciled cr, such as a patch base, nil if it has none
+func (r *Reconciler) storedStatus(object client.Object) *sdkapi.Status {
+	if manager, ok := r.crManager.(MetaStatusCrManager); ok {
+		metaStatus := manager.MetaStatus(object)
+		if metaStatus == nil {
+			return nil
+		}
+		status := &sdkapi.Status{}
+		sdk.StatusFromMeta(metaStatus, status)
+		return status
+	}
 	return r.crManager.Status(object)
 }
 
-func (r *Reconciler) setLastAppliedConfiguration(obj metav1.Object) error {
-	return sdk.SetLastAppliedConfiguration(obj, r.lastAppliedConfigAnnotation)
+func (r *Reconciler) annotation(name string) string {
+	return r.annotationPrefix + "/" + name
+}
+
+func (r *Reconciler) isCreating(ctx context.Context, cr client.Object) (bool, error) {
+	if m, ok := r.crManager.(ContextCrManager); ok {
+		return m.IsCreatingContext(ctx, cr)
+	}
+	return r.crManager.IsCreating(cr)
+}
+
+// getAllResources returns the desired resources of the cr, computed once per reconcile pass
+func (r *Reconciler) getAllResources(ctx context.Context, cr client.Object) ([]client.Object, error) {
+	waves, err := r.desiredWaves(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+	return flatten(waves), nil
 }
 
-func (r *Reconciler) completeUpgrade(logger logr.Logger, cr client.Object, operatorVersion string) error {
-	if err := r.CleanupUnusedResources(logger, cr); err != nil {
+func (r *Reconciler) completeUpgrade(ctx context.Context, logger logr.Logger, cr client.Object, operatorVersion string) error {
+	if err := r.CleanupUnusedResourcesContext(ctx, logger, cr); err != nil {
 		return err
 	}
 
-	status := r.status(cr)
+	status := r.status(ctx, cr)
 	previousVersion := status.ObservedVersion
 	status.ObservedVersion = operatorVersion
 
 	sdk.MarkCrHealthyMessage(cr, status, "DeployCompleted", "Deployment Completed", r.recorder)
-	if err := r.CrUpdateStatus(sdkapi.PhaseDeployed, cr); err != nil {
+	if err := r.CrUpdateStatusContext(ctx, sdkapi.PhaseDeployed, cr); err != nil {
 		return err
 	}
 
@@ -114,6 +114,35 @@ var _ = Describe("Reconciler", func() {
 			Expect(args.config.Status.TargetVersion).To(Equal(newVersion))
 		})
 
+		It("should init the CR with the context variant", func() {
+			args := createArgs(version)
+
+			err := args.reconciler.CrInitContext(context.TODO(), args.config, version)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(args.config.Status.Phase).To(BeEquivalentTo(sdkapi.PhaseDeploying))
+			Expect(args.config.GetFinalizers()).To(ConsistOf(finalizerName))
+		})
+
+		It("should set CR to error state with the context variant", func() {
+			args := createArgs(version)
+			err := args.reconciler.CrErrorContext(context.TODO(), args.config)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(args.config.Status.Phase).To(BeEquivalentTo(sdkapi.PhaseError))
+		})
+
+		It("should set CR version with the context variant", func() {
+			args := createArgs(version)
+			newVersion := "v0.1.0"
+
+			err := args.reconciler.CrSetVersionContext(context.TODO(), args.config, newVersion)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+			Expect(args.config.Status.ObservedVersion).To(Equal(newVersion))
+		})
+
 		It("should register CR watching in controller", func() {
 			args := createArgs(version)
 
@@ -127,6 +156,53 @@ var _ = Describe("Reconciler", func() {
 			t := v.FieldByName("Type").Interface().(client.Object)
 			Expect(t).To(BeAssignableToTypeOf(&testcr.Config{}))
 		})
+
+		It("should pass the reconcile context to hooks, CR manager and callbacks", func() {
+			args := createArgs(version)
+			ctx := context.WithValue(context.TODO(), reconcileContextKey{}, "reconcile")
+
+			var receivers []string
+			receivedBy := func(c context.Context, receiver string) {
+				if c.Value(reconcileContextKey{}) == "reconcile" {
+					receivers = append(receivers, receiver)
+				}
+			}
+			getCache := func() cache.Cache {
+				return nil
+			}
+			args.reconciler = reconciler.NewReconciler(&contextCrManager{receivedBy: receivedBy}, log, args.client, &contextCallbackDispatcher{receivedBy: receivedBy}, args.client.Scheme(), getCache, createVersionLabel, "update-version", "last-applied-config", 0, finalizerName, true, args.recorder).
+				WithController(args.mockController).
+				WithContextPreCreateHook(func(c context.Context, _ client.Object) error {
+					receivedBy(c, "PreCreateHook")
+					return nil
+				}).
+				WithContextSanityChecker(func(c context.Context, _ client.Object, _ logr.Logger) (*reconcile.Result, error) {
+					receivedBy(c, "SanityChecker")
+					return nil, nil
+				}).
+				WithContextPerishablesSynchronizer(func(c context.Context, _ client.Object, _ logr.Logger) error {
+					receivedBy(c, "PerishablesSynchronizer")
+					return nil
+				})
+
+			_, err := args.reconciler.ReconcileContext(ctx, reconcileRequest(args.config.Name), args.version, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(receivers).To(ContainElements("IsCreating", "GetAllResources", "PreCreateHook", "SanityChecker", "PerishablesSynchronizer", string(callbacks.ReconcileStatePreCreate)))
+		})
+
+		It("should reconcile without context with the deprecated methods", func() {
+			args := createArgs(version)
+			_, err := args.reconciler.Reconcile(reconcileRequest(args.config.Name), args.version, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			cr, err := args.reconciler.GetCr(types.NamespacedName{Name: args.config.Name})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(args.reconciler.CrUpdateStatus(sdkapi.PhaseDeploying, cr)).To(Succeed())
+			degraded, err := args.reconciler.CheckDegraded(log, cr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(degraded).To(BeTrue())
+		})
 	})
 
 	Describe("deploying operator", func() {
@@ -731,7 +807,10 @@ func createClient(scheme *runtime.Scheme, objs ...client.Object) client.Client {
 }
 
 func createReconciler(client client.Client, s *runtime.Scheme, recorder record.EventRecorder) *reconciler.Reconciler {
-	crManager := &testcr.ConfigCrManager{}
+	return createReconcilerWithCrManager(&testcr.ConfigCrManager{}, client, s, recorder)
+}
+
+func createReconcilerWithCrManager(crManager reconciler.CrManager, client client.Client, s *runtime.Scheme, recorder record.EventRecorder) *reconciler.Reconciler {
 	getCache := func() cache.Cache {
 		return nil
 	}
@@ -760,6 +839,33 @@ func (m *mockCallbackDispatcher) AddCallback(obj client.Object, cb callbacks.Rec
 	addCallback(obj, cb)
 }
 
+type reconcileContextKey struct{}
+
+type contextCrManager struct {
+	testcr.ConfigCrManager
+	receivedBy func(context.Context, string)
+}
+
+func (m *contextCrManager) IsCreatingContext(ctx context.Context, cr client.Object) (bool, error) {
+	m.receivedBy(ctx, "IsCreating")
+	return m.IsCreating(cr)
+}
+
+func (m *contextCrManager) GetAllResourcesContext(ctx context.Context, cr client.Object) ([]client.Object, error) {
+	m.receivedBy(ctx, "GetAllResources")
+	return m.GetAllResources(cr)
+}
+
+type contextCallbackDispatcher struct {
+	mockCallbackDispatcher
+	receivedBy func(context.Context, string)
+}
+
+func (m *contextCallbackDispatcher) InvokeCallbacksContext(ctx context.Context, _ logr.Logger, cr interface{}, s callbacks.ReconcileState, desiredObj, currentObj client.Object, _ record.EventRecorder) error {
+	m.receivedBy(ctx, string(s))
+	return invokeCallbacks(cr, s, desiredObj, currentObj)
+}
+
 func reconcileRequest(name string) reconcile.Request {
 	return reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
 }
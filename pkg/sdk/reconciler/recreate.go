@@ -0,0 +1,120 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+)
+
+const (
+	recreateResourceFailed  = "RecreateResourceFailed"
+	recreateResourceSuccess = "RecreateResourceSuccess"
+)
+
+// RecreatePolicy decides whether a managed resource is deleted and created again when its update is refused because
+// an immutable field changed
+type RecreatePolicy string
+
+const (
+	// RecreateNever reports the failed update, the default
+	RecreateNever RecreatePolicy = "never"
+	// RecreateDeleteDependents recreates the resource, its dependents (e.g. the pods of a Deployment) are deleted
+	RecreateDeleteDependents RecreatePolicy = "delete-dependents"
+	// RecreateOrphanDependents recreates the resource, its dependents are left running
+	RecreateOrphanDependents RecreatePolicy = "orphan-dependents"
+)
+
+// DefaultRecreatableKinds returns the kinds the Reconciler recreates on immutable field changes unless configured
+// otherwise
+func DefaultRecreatableKinds() map[schema.GroupVersionKind]bool {
+	return map[schema.GroupVersionKind]bool{
+		appsv1.SchemeGroupVersion.WithKind("Deployment"):         true,
+		appsv1.SchemeGroupVersion.WithKind("DaemonSet"):          true,
+		appsv1.SchemeGroupVersion.WithKind("StatefulSet"):        true,
+		batchv1.SchemeGroupVersion.WithKind("Job"):               true,
+		corev1.SchemeGroupVersion.WithKind("Service"):            true,
+		rbacv1.SchemeGroupVersion.WithKind("RoleBinding"):        true,
+		rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"): true,
+	}
+}
+
+// isImmutableFieldError checks whether err is the API server refusing a change of an immutable field
+func isImmutableFieldError(err error) bool {
+	if !errors.IsInvalid(err) {
+		return false
+	}
+	messages := []string{err.Error()}
+	if status, ok := err.(errors.APIStatus); ok && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			messages = append(messages, cause.Message)
+		}
+	}
+	for _, message := range messages {
+		// RoleBindings report "cannot change roleRef"
+		if strings.Contains(message, "field is immutable") || strings.Contains(message, "cannot change") {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldRecreate checks whether the failed update of obj has to be followed by recreating it
+func (r *Reconciler) shouldRecreate(obj client.Object, err error) bool {
+	if r.recreatePolicy == RecreateNever || !isImmutableFieldError(err) {
+		return false
+	}
+	gvk, gvkErr := apiutil.GVKForObject(obj, r.scheme)
+	return gvkErr == nil && r.recreatableKinds[gvk]
+}
+
+// recreateResource deletes currentObj and creates desiredObj instead. Unless the dependents are deleted in the
+// background, the old object may still be terminating; its creation is then retried by the next reconciliation.
+func (r *Reconciler) recreateResource(ctx context.Context, logger logr.Logger, cr client.Object, desiredObj, currentObj client.Object, operatorVersion string) error {
+	logger.Info("Recreating resource with changed immutable fields",
+		"namespace", desiredObj.GetNamespace(),
+		"name", desiredObj.GetName(),
+		"type", fmt.Sprintf("%T", desiredObj),
+		"policy", r.recreatePolicy)
+
+	// PRE_DELETE callback
+	if err := r.InvokeCallbacksContext(ctx, logger, cr, callbacks.ReconcileStatePreDelete, desiredObj, currentObj, r.recorder); err != nil {
+		r.recorder.Event(cr, corev1.EventTypeWarning, recreateResourceFailed, fmt.Sprintf("Failed to recreate resource %s, %v", desiredObj.GetName(), err))
+		return err
+	}
+
+	propagationPolicy := metav1.DeletePropagationBackground
+	if r.recreatePolicy == RecreateOrphanDependents {
+		propagationPolicy = metav1.DeletePropagationOrphan
+	}
+	err := r.client.Delete(ctx, currentObj, &client.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+		Preconditions:     &metav1.Preconditions{UID: &[]types.UID{currentObj.GetUID()}[0]},
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "")
+		r.recorder.Event(cr, corev1.EventTypeWarning, recreateResourceFailed, fmt.Sprintf("Failed to recreate resource %s, %v", desiredObj.GetName(), err))
+		return r.resourceError(desiredObj, ResourceOperationDelete, err)
+	}
+
+	if err = r.createResource(ctx, logger, cr, desiredObj, operatorVersion); err != nil {
+		r.recorder.Event(cr, corev1.EventTypeWarning, recreateResourceFailed, fmt.Sprintf("Failed to recreate resource %s, %v", desiredObj.GetName(), err))
+		return err
+	}
+
+	r.recorder.Event(cr, corev1.EventTypeNormal, recreateResourceSuccess, fmt.Sprintf("Successfully recreated resource %T %s with changed immutable fields", desiredObj, desiredObj.GetName()))
+	return nil
+}
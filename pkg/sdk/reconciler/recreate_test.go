@@ -0,0 +1,123 @@
+package reconciler_test
+
+import (
+	"context"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Recreating resources", func() {
+	operatorDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}}
+	var states []callbacks.ReconcileState
+	var deleteOptions *client.DeleteOptions
+
+	BeforeEach(func() {
+		states = nil
+		deleteOptions = nil
+		invokeCallbacks = func(_ interface{}, s callbacks.ReconcileState, desiredObj client.Object, _ client.Object) error {
+			if desiredObj != nil && desiredObj.GetName() == testcr.OperatorDeploymentName {
+				states = append(states, s)
+			}
+			return nil
+		}
+	})
+
+	// deployWithChangedSelector deploys the resources with a client refusing selector changes, as the API server does,
+	// and changes the selector of the deployment behind the back of the reconciler
+	deployWithChangedSelector := func(policy reconciler.RecreatePolicy) (*args, *appsv1.Deployment) {
+		args := createArgs(version)
+		c := args.client.(client.WithWatch)
+		args.client = interceptor.NewClient(c, interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if deployment, ok := obj.(*appsv1.Deployment); ok {
+					stored := &appsv1.Deployment{}
+					if err := c.Get(ctx, client.ObjectKeyFromObject(obj), stored); err != nil {
+						return err
+					}
+					if !reflect.DeepEqual(stored.Spec.Selector, deployment.Spec.Selector) {
+						return errors.NewInvalid(appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind(), obj.GetName(), field.ErrorList{
+							field.Invalid(field.NewPath("spec", "selector"), deployment.Spec.Selector, "field is immutable"),
+						})
+					}
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				deleteOptions = &client.DeleteOptions{}
+				deleteOptions.ApplyOptions(opts)
+				return c.Delete(ctx, obj, opts...)
+			},
+		})
+		args.reconciler = createReconcilerWithCrManager(&testcr.ConfigCrManager{}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithRecreatePolicy(policy)
+		doReconcile(args)
+
+		deployment, err := getDeployment(c, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"old": "selector"}}
+		Expect(c.Update(context.TODO(), deployment)).To(Succeed())
+		states = nil
+		drainEvents(args.recorder)
+		return args, deployment
+	}
+
+	It("should report the failed update by default", func() {
+		args, changed := deployWithChangedSelector(reconciler.RecreateNever)
+
+		doReconcileError(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.ResourceVersion).To(Equal(changed.ResourceVersion))
+		Expect(deployment.Spec.Selector).To(Equal(changed.Spec.Selector))
+		Expect(deleteOptions).To(BeNil())
+	})
+
+	It("should recreate resource with changed immutable field", func() {
+		args, changed := deployWithChangedSelector(reconciler.RecreateDeleteDependents)
+
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.ResourceVersion).ToNot(Equal(changed.ResourceVersion))
+		Expect(deployment.Spec.Selector.MatchLabels).ToNot(HaveKey("old"))
+		Expect(deployment.Annotations).To(HaveKey("last-applied-config"))
+		Expect(deployment.Annotations["last-applied-config"]).ToNot(ContainSubstring("last-applied-config"))
+		Expect(*deleteOptions.PropagationPolicy).To(Equal(metav1.DeletePropagationBackground))
+		Expect(states).To(Equal([]callbacks.ReconcileState{
+			callbacks.ReconcileStatePostRead,
+			callbacks.ReconcileStatePreUpdate,
+			callbacks.ReconcileStatePreDelete,
+			callbacks.ReconcileStatePreCreate,
+			callbacks.ReconcileStatePostCreate,
+		}))
+		Expect(drainEvents(args.recorder)).To(ContainElement(
+			"Normal RecreateResourceSuccess Successfully recreated resource *v1.Deployment " + testcr.OperatorDeploymentName + " with changed immutable fields"))
+	})
+
+	It("should orphan dependents when asked to", func() {
+		args, changed := deployWithChangedSelector(reconciler.RecreateOrphanDependents)
+
+		doReconcile(args)
+
+		deployment, err := getDeployment(args.client, operatorDeployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployment.ResourceVersion).ToNot(Equal(changed.ResourceVersion))
+		Expect(*deleteOptions.PropagationPolicy).To(Equal(metav1.DeletePropagationOrphan))
+	})
+})
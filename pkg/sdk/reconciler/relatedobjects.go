@@ -0,0 +1,145 @@
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	objectreferences "github.com/openshift/custom-resource-status/objectreferences/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+)
+
+// DefaultRelatedObjectsDebounce is the minimal interval between status writes caused only by new related objects,
+// unless configured otherwise
+const DefaultRelatedObjectsDebounce = 10 * time.Second
+
+// objectReference returns the reference of obj listed in the related objects of the CR
+func (r *Reconciler) objectReference(obj client.Object) corev1.ObjectReference {
+	apiVersion, kind := r.groupVersionKind(obj).ToAPIVersionAndKind()
+	return corev1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// appliedResources returns the resources that exist after their reconciliation, i.e. all but the ones failed to be
+// created
+func (r *Reconciler) appliedResources(resources []client.Object, errs []error) []client.Object {
+	var applied []client.Object
+	for _, obj := range resources {
+		if resourceErr := r.findResourceError(errs, obj); resourceErr == nil || resourceErr.Operation != ResourceOperationCreate {
+			applied = append(applied, obj)
+		}
+	}
+	return applied
+}
+
+// syncRelatedObjects rebuilds the related objects of the cr: the applied resources missing from them are added, and the
+// entries of the objects neither desired nor retained by their reconcile policy are removed. Within a reconcile pass
+// the added ones are deferred to flushRelatedObjects, written along with other changes of the status or once the
+// debounce interval since the last write of the related objects elapsed.
+func (r *Reconciler) syncRelatedObjects(ctx context.Context, logger logr.Logger, cr client.Object, applied []client.Object) error {
+	desired, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return err
+	}
+	_, retained, err := r.getUndesiredResources(ctx, logger, cr)
+	if err != nil {
+		return err
+	}
+	var kept []corev1.ObjectReference
+	for _, objs := range [][]client.Object{desired, retained} {
+		for _, obj := range objs {
+			kept = append(kept, r.objectReference(obj))
+		}
+	}
+
+	status := r.status(ctx, cr)
+	var stale []corev1.ObjectReference
+	for _, ref := range status.RelatedObjects {
+		if found, err := objectreferences.FindObjectReference(kept, ref); err != nil {
+			return err
+		} else if found == nil {
+			stale = append(stale, ref)
+		}
+	}
+	for _, ref := range stale {
+		if err := objectreferences.RemoveObjectReference(&status.RelatedObjects, ref); err != nil {
+			return err
+		}
+	}
+
+	var added []corev1.ObjectReference
+	for _, obj := range applied {
+		ref := r.objectReference(obj)
+		if found, err := objectreferences.FindObjectReference(status.RelatedObjects, ref); err != nil {
+			return err
+		} else if found == nil {
+			added = append(added, ref)
+		}
+	}
+	if len(stale) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	if batch := r.statusBatch(ctx, cr); batch != nil {
+		batch.relatedObjects = append(batch.relatedObjects, added...)
+		// only the new entries are debounced
+		batch.pending = batch.pending || len(stale) > 0
+		return nil
+	}
+	if err := setRelatedObjects(status, added); err != nil {
+		return err
+	}
+	r.relatedObjectsWrites.Store(cr.GetUID(), time.Now())
+	return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
+
+// flushRelatedObjects adds the related objects deferred by the reconcile pass to the status of the cr unless
+// debounced; the returned duration is the time left until they may be written
+func (r *Reconciler) flushRelatedObjects(ctx context.Context, cr client.Object) (time.Duration, error) {
+	batch := r.statusBatch(ctx, cr)
+	if batch == nil || len(batch.relatedObjects) == 0 {
+		return 0, nil
+	}
+	if !batch.pending {
+		if written, ok := r.relatedObjectsWrites.Load(cr.GetUID()); ok {
+			if left := r.relatedObjectsDebounce - time.Since(written.(time.Time)); left > 0 {
+				return left, nil
+			}
+		}
+	}
+
+	if err := setRelatedObjects(r.status(ctx, cr), batch.relatedObjects); err != nil {
+		return 0, err
+	}
+	batch.relatedObjects = nil
+	batch.pending = true
+	r.relatedObjectsWrites.Store(cr.GetUID(), time.Now())
+	return 0, nil
+}
+
+// setRelatedObjects adds the refs to the related objects of the status
+func setRelatedObjects(status *sdkapi.Status, refs []corev1.ObjectReference) error {
+	for _, ref := range refs {
+		if err := objectreferences.SetObjectReference(&status.RelatedObjects, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeRelatedObject removes obj from the related objects of the cr, the returned flag tells whether it was listed
+func (r *Reconciler) removeRelatedObject(ctx context.Context, cr client.Object, obj client.Object) (bool, error) {
+	ref := r.objectReference(obj)
+	status := r.status(ctx, cr)
+	if found, err := objectreferences.FindObjectReference(status.RelatedObjects, ref); err != nil || found == nil {
+		return false, err
+	}
+	return true, objectreferences.RemoveObjectReference(&status.RelatedObjects, ref)
+}
@@ -0,0 +1,146 @@
+package reconciler_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+// extraResourcesCrManager manages the extra resources along with the ones of ConfigCrManager
+type extraResourcesCrManager struct {
+	testcr.ConfigCrManager
+	extra func() []client.Object
+}
+
+func (m *extraResourcesCrManager) GetAllResources(cr client.Object) ([]client.Object, error) {
+	resources, err := m.ConfigCrManager.GetAllResources(cr)
+	if err != nil {
+		return nil, err
+	}
+	return append(resources, m.extra()...), nil
+}
+
+var _ = Describe("Related objects", func() {
+	operatorDeployment := corev1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  testcr.Namespace,
+		Name:       testcr.OperatorDeploymentName,
+	}
+	extraConfigMap := corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Namespace:  testcr.Namespace,
+		Name:       "extra",
+	}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// deploy deploys the CR with a CR manager managing no extra resources until they are set
+	deploy := func() (*args, *extraResourcesCrManager) {
+		args := createArgs(version)
+		crManager := &extraResourcesCrManager{extra: func() []client.Object { return nil }}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		return args, crManager
+	}
+
+	It("should list the applied resources", func() {
+		args, _ := deploy()
+		Expect(args.config.Status.RelatedObjects).To(Equal([]corev1.ObjectReference{operatorDeployment}))
+	})
+
+	It("should debounce the writes of new related objects", func() {
+		args, crManager := deploy()
+		args.reconciler.WithRelatedObjectsDebounce(time.Hour)
+		crManager.extra = func() []client.Object {
+			return []client.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "extra", Namespace: testcr.Namespace}}}
+		}
+
+		result, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), args.version, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+		args.config, err = getConfig(args.client, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.config.Status.RelatedObjects).To(Equal([]corev1.ObjectReference{operatorDeployment}))
+
+		args.reconciler.WithRelatedObjectsDebounce(0)
+		doReconcile(args)
+		Expect(args.config.Status.RelatedObjects).To(Equal([]corev1.ObjectReference{operatorDeployment, extraConfigMap}))
+	})
+
+	It("should prune the removed resources", func() {
+		args, crManager := deploy()
+		crManager.extra = func() []client.Object {
+			deployment := testcr.ResourceBuilder.CreateOperatorDeployment("extra", testcr.Namespace, "key", "value", "svc-account", 1, corev1.PodSpec{})
+			return []client.Object{deployment}
+		}
+		doReconcile(args)
+		extraDeployment := operatorDeployment
+		extraDeployment.Name = "extra"
+		Expect(args.config.Status.RelatedObjects).To(ContainElement(extraDeployment))
+
+		crManager.extra = func() []client.Object { return nil }
+		Expect(args.reconciler.CleanupUnusedResourcesContext(context.TODO(), log, args.config)).To(Succeed())
+		_, err := getDeployment(args.client, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "extra", Namespace: testcr.Namespace}})
+		Expect(err).To(HaveOccurred())
+
+		args.config, err = getConfig(args.client, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.config.Status.RelatedObjects).To(Equal([]corev1.ObjectReference{operatorDeployment}))
+	})
+
+	It("should drop the resources no longer desired but keep the retained ones", func() {
+		args, crManager := deploy()
+		args.reconciler.WithRelatedObjectsDebounce(0)
+		crManager.extra = func() []client.Object {
+			return []client.Object{
+				testcr.ResourceBuilder.CreateOperatorDeployment("extra", testcr.Namespace, "key", "value", "svc-account", 1, corev1.PodSpec{}),
+				testcr.ResourceBuilder.CreateOperatorDeployment("retained", testcr.Namespace, "key", "value", "svc-account", 1, corev1.PodSpec{}),
+			}
+		}
+		doReconcile(args)
+		extraDeployment := operatorDeployment
+		extraDeployment.Name = "extra"
+		retainedDeployment := operatorDeployment
+		retainedDeployment.Name = "retained"
+		Expect(args.config.Status.RelatedObjects).To(Equal([]corev1.ObjectReference{operatorDeployment, extraDeployment, retainedDeployment}))
+
+		retained, err := getDeployment(args.client, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "retained", Namespace: testcr.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+		retained.Annotations = map[string]string{reconciler.DefaultAnnotationPrefix + "/" + reconciler.ReconcilePolicyAnnotation: string(reconciler.ReconcilePolicyUnmanaged)}
+		Expect(args.client.Update(context.TODO(), retained)).To(Succeed())
+
+		// removed without cleaning up the unused resources
+		crManager.extra = func() []client.Object { return nil }
+		doReconcile(args)
+		Expect(args.config.Status.RelatedObjects).To(Equal([]corev1.ObjectReference{operatorDeployment, retainedDeployment}))
+	})
+
+	It("should refuse negative debounce intervals", func() {
+		args := createArgs(version)
+		Expect(func() { args.reconciler.WithRelatedObjectsDebounce(-time.Second) }).To(Panic())
+		Expect(func() { args.reconciler.WithRelatedObjectsDebounce(0) }).ToNot(Panic())
+		Expect(reconciler.DefaultRelatedObjectsDebounce).To(BeNumerically(">", 0))
+	})
+})
@@ -0,0 +1,153 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+)
+
+// maxResourceErrorLength is the length the errors reported in the resource status are truncated to
+const maxResourceErrorLength = 512
+
+// resourceStatusEnabled checks whether the resource status of the CR is maintained
+func (r *Reconciler) resourceStatusEnabled() bool {
+	return r.resourceStatusLimit > 0
+}
+
+// findResourceError returns the failure of obj among the failures returned by reconcileResources, nil if it didn't
+// fail
+func (r *Reconciler) findResourceError(errs []error, obj client.Object) *ResourceError {
+	for _, err := range errs {
+		var resourceErr *ResourceError
+		if errors.As(err, &resourceErr) && resourceErr.GroupVersionKind == r.groupVersionKind(obj) &&
+			resourceErr.Namespace == obj.GetNamespace() && resourceErr.Name == obj.GetName() {
+			return resourceErr
+		}
+	}
+	return nil
+}
+
+// recordResourceSyncs records the result of the reconciliation of the reconciled resources in the resource status of
+// the cr; the other managed resources keep their last result
+func (r *Reconciler) recordResourceSyncs(ctx context.Context, cr client.Object, reconciled []client.Object, errs []error) error {
+	if !r.resourceStatusEnabled() {
+		return nil
+	}
+
+	resources, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return err
+	}
+	synced := map[corev1.ObjectReference]*ResourceError{}
+	for _, obj := range reconciled {
+		synced[r.objectReference(obj)] = r.findResourceError(errs, obj)
+	}
+
+	previous := map[corev1.ObjectReference]sdkapi.ResourceStatus{}
+	for _, entry := range r.resourceStatuses(ctx, cr) {
+		previous[resourceStatusReference(entry)] = entry
+	}
+	var entries []sdkapi.ResourceStatus
+	for _, obj := range resources {
+		ref := r.objectReference(obj)
+		entry, ok := previous[ref]
+		if !ok {
+			entry = sdkapi.ResourceStatus{
+				APIVersion: ref.APIVersion,
+				Kind:       ref.Kind,
+				Namespace:  ref.Namespace,
+				Name:       ref.Name,
+				SyncResult: sdkapi.ResourceSyncPending,
+			}
+		}
+		if resourceErr, ok := synced[ref]; ok {
+			entry.SyncResult, entry.Error = sdkapi.ResourceSyncSynced, ""
+			if resourceErr != nil {
+				entry.SyncResult = sdkapi.ResourceSyncFailed
+				entry.Error = truncate(fmt.Sprintf("failed to %s: %v", resourceErr.Operation, resourceErr.Err), maxResourceErrorLength)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return r.setResourceStatuses(ctx, cr, entries)
+}
+
+// recordResourceReadiness records the readiness of the checked resources in the resource status of the cr
+func (r *Reconciler) recordResourceReadiness(ctx context.Context, cr client.Object, readiness map[corev1.ObjectReference]bool) error {
+	if !r.resourceStatusEnabled() {
+		return nil
+	}
+
+	var entries []sdkapi.ResourceStatus
+	for _, entry := range r.resourceStatuses(ctx, cr) {
+		if ready, ok := readiness[resourceStatusReference(entry)]; ok {
+			entry.Ready = ready
+		}
+		entries = append(entries, entry)
+	}
+	return r.setResourceStatuses(ctx, cr, entries)
+}
+
+// resourceStatuses returns the uncapped resource status of the cr, falling back to the capped one after a restart
+func (r *Reconciler) resourceStatuses(ctx context.Context, cr client.Object) []sdkapi.ResourceStatus {
+	if entries, ok := r.uncappedResourceStatuses.Load(cr.GetUID()); ok {
+		return entries.([]sdkapi.ResourceStatus)
+	}
+	return r.status(ctx, cr).Resources
+}
+
+// setResourceStatuses sets the resource status of the cr to the entries capped to the configured limit, keeping the
+// failed and not ready resources first, and writes it if it changed. The entries left out are kept in memory, so that
+// they don't lose their state.
+func (r *Reconciler) setResourceStatuses(ctx context.Context, cr client.Object, entries []sdkapi.ResourceStatus) error {
+	r.uncappedResourceStatuses.Store(cr.GetUID(), entries)
+	if len(entries) > r.resourceStatusLimit {
+		entries = append([]sdkapi.ResourceStatus(nil), entries...)
+		sort.SliceStable(entries, func(i, j int) bool {
+			return !healthyResource(entries[i]) && healthyResource(entries[j])
+		})
+		entries = entries[:r.resourceStatusLimit]
+	}
+
+	status := r.status(ctx, cr)
+	if reflect.DeepEqual(status.Resources, entries) {
+		return nil
+	}
+	status.Resources = entries
+	return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
+
+// healthyResource checks whether the resource of the entry was synced and is ready
+func healthyResource(entry sdkapi.ResourceStatus) bool {
+	return entry.SyncResult == sdkapi.ResourceSyncSynced && entry.Ready
+}
+
+// resourceStatusReference returns the reference of the resource of the entry
+func resourceStatusReference(entry sdkapi.ResourceStatus) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: entry.APIVersion,
+		Kind:       entry.Kind,
+		Namespace:  entry.Namespace,
+		Name:       entry.Name,
+	}
+}
+
+// truncate shortens message to at most max bytes, without splitting a rune
+func truncate(message string, max int) string {
+	if len(message) <= max {
+		return message
+	}
+	end := max - 3
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end] + "..."
+}
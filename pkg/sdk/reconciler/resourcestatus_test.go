@@ -0,0 +1,146 @@
+package reconciler_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Resource status", func() {
+	var refuse bool
+	var refusal string
+
+	BeforeEach(func() {
+		refuse = false
+		refusal = "refused"
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// createResourceStatusArgs returns args with a client refusing to update Deployments with the refusal while refuse
+	// is set
+	createResourceStatusArgs := func(crManager reconciler.CrManager, limit int) *args {
+		args := createArgs(version)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*appsv1.Deployment); ok && refuse {
+					return fmt.Errorf("%s", refusal)
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithResourceStatus(limit)
+		return args
+	}
+
+	operatorDeployment := func(syncResult sdkapi.ResourceSyncResult, ready bool) sdkapi.ResourceStatus {
+		return sdkapi.ResourceStatus{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  testcr.Namespace,
+			Name:       testcr.OperatorDeploymentName,
+			SyncResult: syncResult,
+			Ready:      ready,
+		}
+	}
+
+	It("should be disabled by default", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Resources).To(BeEmpty())
+	})
+
+	It("should report the sync result and the readiness of the resources", func() {
+		args := createResourceStatusArgs(&testcr.ConfigCrManager{}, 10)
+
+		doReconcile(args)
+		Expect(args.config.Status.Resources).To(Equal([]sdkapi.ResourceStatus{operatorDeployment(sdkapi.ResourceSyncSynced, false)}))
+
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Resources).To(Equal([]sdkapi.ResourceStatus{operatorDeployment(sdkapi.ResourceSyncSynced, true)}))
+	})
+
+	It("should report the error of the failed resources until they are synced", func() {
+		crManager := &mutatingCrManager{mutate: func(*appsv1.Deployment) {}}
+		args := createResourceStatusArgs(crManager, 10)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		refuse = true
+		crManager.mutate = func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}
+		doReconcileError(args)
+		failed := operatorDeployment(sdkapi.ResourceSyncFailed, true)
+		failed.Error = "failed to update: refused"
+		Expect(args.config.Status.Resources).To(Equal([]sdkapi.ResourceStatus{failed}))
+
+		refuse = false
+		doReconcile(args)
+		Expect(args.config.Status.Resources).To(Equal([]sdkapi.ResourceStatus{operatorDeployment(sdkapi.ResourceSyncSynced, true)}))
+	})
+
+	It("should truncate long errors on a rune boundary", func() {
+		crManager := &mutatingCrManager{mutate: func(*appsv1.Deployment) {}}
+		args := createResourceStatusArgs(crManager, 10)
+		doReconcile(args)
+
+		refuse = true
+		refusal = strings.Repeat("é", 300)
+		crManager.mutate = func(deployment *appsv1.Deployment) {
+			deployment.Spec.MinReadySeconds = 5
+		}
+		doReconcileError(args)
+		Expect(args.config.Status.Resources).To(HaveLen(1))
+		message := args.config.Status.Resources[0].Error
+		Expect(len(message)).To(BeNumerically("<=", 512))
+		Expect(message).To(HavePrefix("failed to update: éé"))
+		Expect(message).To(HaveSuffix("é..."))
+		Expect(utf8.ValidString(message)).To(BeTrue())
+	})
+
+	It("should keep the failed and not ready resources within the limit", func() {
+		crManager := &extraResourcesCrManager{extra: func() []client.Object {
+			var configMaps []client.Object
+			for i := 0; i < 3; i++ {
+				configMaps = append(configMaps, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("extra-%d", i),
+					Namespace: testcr.Namespace,
+				}})
+			}
+			return configMaps
+		}}
+		args := createResourceStatusArgs(crManager, 2)
+
+		doReconcile(args)
+		doReconcile(args)
+		Expect(args.config.Status.Resources).To(HaveLen(2))
+		Expect(args.config.Status.Resources[0]).To(Equal(operatorDeployment(sdkapi.ResourceSyncSynced, false)))
+		Expect(args.config.Status.Resources[1].Name).To(Equal("extra-0"))
+		Expect(args.config.Status.Resources[1].Ready).To(BeTrue())
+	})
+
+	It("should refuse invalid limits", func() {
+		args := createArgs(version)
+		Expect(func() { args.reconciler.WithResourceStatus(0) }).To(Panic())
+	})
+})
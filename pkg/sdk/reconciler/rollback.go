@@ -0,0 +1,337 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// UpgradeDeadlineAction is what the Reconciler does with an upgrade not completed within the deadline
+type UpgradeDeadlineAction string
+
+const (
+	// UpgradeDeadlineReport sets the UpgradeTimeout reason on the Degraded condition of the CR, the upgrade goes on
+	UpgradeDeadlineReport UpgradeDeadlineAction = "Report"
+	// UpgradeDeadlineFail moves the CR to the Error phase
+	UpgradeDeadlineFail UpgradeDeadlineAction = "Fail"
+	// UpgradeDeadlineRollback restores the managed resources of the last successfully deployed version from the
+	// snapshot taken when it was deployed, then moves the CR to the Error phase
+	UpgradeDeadlineRollback UpgradeDeadlineAction = "Rollback"
+)
+
+const (
+	upgradeTimeout         = "UpgradeTimeout"
+	upgradeRollbackStarted = "UpgradeRollbackStarted"
+	upgradeRolledBack      = "UpgradeRolledBack"
+	upgradeRollbackFailed  = "UpgradeRollbackFailed"
+
+	rollbackResourceSuccess = "RollbackResourceSuccess"
+	rollbackResourceFailed  = "RollbackResourceFailed"
+	snapshotFailed          = "SnapshotFailed"
+
+	snapshotVersionKey = "version"
+	snapshotObjectsKey = "objects"
+
+	// maxSnapshotSize is the size of the compressed snapshot the reconciler refuses to store, well below the size
+	// limit of a Secret
+	maxSnapshotSize = 512 * 1024
+)
+
+// secretGroupKind is the kind of the managed resources left out of the snapshot, so that their data isn't copied
+var secretGroupKind = schema.GroupKind{Kind: "Secret"}
+
+// upgradeFailed checks whether the upgrade to operatorVersion has already failed the deadline, in which case the
+// resources are left alone until a different operator version takes over
+func (r *Reconciler) upgradeFailed(ctx context.Context, cr client.Object, operatorVersion string) bool {
+	status := r.status(ctx, cr)
+	if status.Phase != sdkapi.PhaseError || status.TargetVersion != operatorVersion {
+		return false
+	}
+	degraded := conditions.FindStatusCondition(status.Conditions, conditions.ConditionDegraded)
+	return degraded != nil && (degraded.Reason == upgradeTimeout || degraded.Reason == upgradeRolledBack || degraded.Reason == upgradeRollbackFailed)
+}
+
+// upgradeTimeLeft returns the time left until the upgrade deadline and whether an upgrade with a deadline is in progress
+func (r *Reconciler) upgradeTimeLeft(ctx context.Context, cr client.Object) (time.Duration, bool) {
+	status := r.status(ctx, cr)
+	if r.upgradeDeadline == 0 || status.Phase != sdkapi.PhaseUpgrading {
+		return 0, false
+	}
+	started, ok := phaseStartTime(status)
+	if !ok {
+		return 0, false
+	}
+	return time.Until(started.Add(r.upgradeDeadline)), true
+}
+
+// upgradeTimeoutMessage describes the upgrade not completed within the deadline
+func (r *Reconciler) upgradeTimeoutMessage(status *sdkapi.Status) string {
+	return fmt.Sprintf("Upgrade from version %s to %s did not complete within %s", status.ObservedVersion, status.TargetVersion, r.upgradeDeadline)
+}
+
+// handleUpgradeDeadline reports, fails or rolls back the upgrade in progress if its deadline expired; the returned flag
+// tells whether it failed or rolled back the upgrade
+func (r *Reconciler) handleUpgradeDeadline(ctx context.Context, logger logr.Logger, cr client.Object, operatorVersion string) (bool, error) {
+	if left, ok := r.upgradeTimeLeft(ctx, cr); !ok || left > 0 {
+		return false, nil
+	}
+
+	status := r.status(ctx, cr)
+	message := r.upgradeTimeoutMessage(status)
+	if r.upgradeDeadlineAction == UpgradeDeadlineReport {
+		return false, r.reportTimeout(ctx, logger, cr)
+	}
+	logger.Info("Upgrade deadline exceeded", "from version", status.ObservedVersion, "to version", operatorVersion, "deadline", r.upgradeDeadline)
+
+	reason := upgradeTimeout
+	if r.upgradeDeadlineAction == UpgradeDeadlineRollback {
+		r.recorder.Event(cr, corev1.EventTypeWarning, upgradeRollbackStarted, message+fmt.Sprintf(", rolling back to %s", status.ObservedVersion))
+		if err := r.rollback(ctx, logger, cr); err != nil {
+			logger.Error(err, "Rollback failed")
+			reason = upgradeRollbackFailed
+			message = fmt.Sprintf("%s, rollback to %s failed: %v", message, status.ObservedVersion, err)
+		} else {
+			reason = upgradeRolledBack
+			message = fmt.Sprintf("%s, rolled back to %s", message, status.ObservedVersion)
+		}
+	}
+
+	sdk.MarkCrFailed(cr, status, reason, message, r.recorder)
+	return true, r.CrUpdateStatusContext(ctx, sdkapi.PhaseError, cr)
+}
+
+// snapshotKey returns the key of the snapshot of the last successfully deployed version
+func (r *Reconciler) snapshotKey(cr client.Object) (client.ObjectKey, error) {
+	return r.crSnapshotKey(cr, "lifecycle-snapshot")
+}
+
+// crSnapshotKey returns the key of a snapshot of the state of the cr, kept in the namespace of the cr unless set by
+// WithSnapshotNamespace
+func (r *Reconciler) crSnapshotKey(cr client.Object, suffix string) (client.ObjectKey, error) {
+	namespace := r.snapshotNamespace
+	if namespace == "" {
+		namespace = cr.GetNamespace()
+	}
+	if namespace == "" {
+		return client.ObjectKey{}, fmt.Errorf("no namespace to store the snapshot of cluster scoped %s in", cr.GetName())
+	}
+	return client.ObjectKey{Namespace: namespace, Name: cr.GetName() + "-" + suffix}, nil
+}
+
+// storeSnapshot records the live state of the managed resources deployed by the observed version in a compressed
+// Secret, which is rewritten whenever that state changed since. Managed Secrets are left out of the snapshot and so
+// aren't rolled back.
+func (r *Reconciler) storeSnapshot(ctx context.Context, logger logr.Logger, cr client.Object) error {
+	if r.upgradeDeadlineAction != UpgradeDeadlineRollback {
+		return nil
+	}
+
+	key, err := r.snapshotKey(cr)
+	if err != nil {
+		return err
+	}
+	version := r.status(ctx, cr).ObservedVersion
+
+	snapshot := &corev1.Secret{}
+	exists := true
+	if err = r.client.Get(ctx, key, snapshot); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		exists = false
+	}
+	resources, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return err
+	}
+	var objects []map[string]interface{}
+	for _, resource := range resources {
+		if r.groupVersionKind(resource).GroupKind() == secretGroupKind {
+			continue
+		}
+		object, err := r.snapshotObject(ctx, resource)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, object)
+	}
+	data, err := json.Marshal(objects)
+	if err != nil {
+		return err
+	}
+	if exists && string(snapshot.Data[snapshotVersionKey]) == version {
+		if stored, err := sdk.Decompress(snapshot.Data[snapshotObjectsKey]); err == nil && bytes.Equal(stored, data) {
+			return nil
+		}
+	}
+	compressed, err := sdk.Compress(data)
+	if err != nil {
+		return err
+	}
+	if len(compressed) > maxSnapshotSize {
+		return fmt.Errorf("compressed snapshot of version %s has %d bytes, more than %d allowed", version, len(compressed), maxSnapshotSize)
+	}
+
+	snapshot.Name = key.Name
+	snapshot.Namespace = key.Namespace
+	snapshot.Data = map[string][]byte{
+		snapshotVersionKey: []byte(version),
+		snapshotObjectsKey: compressed,
+	}
+	// not labeled with the create version label, so that it isn't considered unused
+	if err = controllerutil.SetOwnerReference(cr, snapshot, r.scheme); err != nil {
+		return err
+	}
+
+	if exists {
+		err = r.client.Update(ctx, snapshot)
+	} else {
+		err = r.client.Create(ctx, snapshot)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Stored snapshot of managed resources", "version", version, "namespace", key.Namespace, "name", key.Name)
+	return nil
+}
+
+// snapshotObject reads the live state of obj without the status and the server-maintained metadata
+func (r *Reconciler) snapshotObject(ctx context.Context, obj client.Object) (map[string]interface{}, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	currentObj := sdk.NewDefaultInstance(obj)
+	if err = r.client.Get(ctx, client.ObjectKeyFromObject(obj), currentObj); err != nil {
+		return nil, err
+	}
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(currentObj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: object}
+	u.SetGroupVersionKind(gvk)
+	u.SetResourceVersion("")
+	u.SetUID("")
+	u.SetGeneration(0)
+	u.SetCreationTimestamp(metav1.Time{})
+	u.SetManagedFields(nil)
+	unstructured.RemoveNestedField(u.Object, "status")
+	return u.Object, nil
+}
+
+// rollback restores the managed resources from the snapshot and removes the ones the snapshot doesn't contain
+func (r *Reconciler) rollback(ctx context.Context, logger logr.Logger, cr client.Object) error {
+	key, err := r.snapshotKey(cr)
+	if err != nil {
+		return err
+	}
+	snapshot := &corev1.Secret{}
+	if err = r.client.Get(ctx, key, snapshot); err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %v", key, err)
+	}
+	if version := r.status(ctx, cr).ObservedVersion; string(snapshot.Data[snapshotVersionKey]) != version {
+		return fmt.Errorf("snapshot %s is of version %s, not %s", key, snapshot.Data[snapshotVersionKey], version)
+	}
+
+	data, err := sdk.Decompress(snapshot.Data[snapshotObjectsKey])
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %v", key, err)
+	}
+	var objects []map[string]interface{}
+	if err = json.Unmarshal(data, &objects); err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %v", key, err)
+	}
+
+	restored := map[string]bool{}
+	for _, object := range objects {
+		obj := &unstructured.Unstructured{Object: object}
+		restored[resourceID(obj.GroupVersionKind(), obj)] = true
+		if err = r.restoreObject(ctx, obj); err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, rollbackResourceFailed, fmt.Sprintf("Failed to roll back resource %s %s, %v", obj.GetKind(), obj.GetName(), err))
+			return err
+		}
+		logger.Info("Resource rolled back", "namespace", obj.GetNamespace(), "name", obj.GetName(), "kind", obj.GetKind())
+		r.recorder.Event(cr, corev1.EventTypeNormal, rollbackResourceSuccess, fmt.Sprintf("Successfully rolled back resource %s %s", obj.GetKind(), obj.GetName()))
+	}
+
+	return r.deleteNotRestored(ctx, logger, cr, restored)
+}
+
+// restoreObject brings the cluster counterpart of obj to the snapshot state
+func (r *Reconciler) restoreObject(ctx context.Context, obj *unstructured.Unstructured) error {
+	currentObj := &unstructured.Unstructured{}
+	currentObj.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(obj), currentObj); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		return r.client.Create(ctx, obj)
+	}
+	obj.SetResourceVersion(currentObj.GetResourceVersion())
+	return r.client.Update(ctx, obj)
+}
+
+// deleteNotRestored removes the resources controlled by the cr that were created by the failed upgrade, except for
+// Secrets, which the snapshot doesn't contain
+func (r *Reconciler) deleteNotRestored(ctx context.Context, logger logr.Logger, cr client.Object, restored map[string]bool) error {
+	ls, err := labels.Parse(r.createVersionLabel)
+	if err != nil {
+		return err
+	}
+
+	for _, lt := range r.crManager.GetDependantResourcesListObjects() {
+		if err = r.client.List(ctx, lt, &client.ListOptions{LabelSelector: ls}); err != nil {
+			return err
+		}
+
+		items := reflect.ValueOf(lt).Elem().FieldByName("Items")
+		for i := 0; i < items.Len(); i++ {
+			obj := items.Index(i).Addr().Interface().(client.Object)
+			gvk, err := apiutil.GVKForObject(obj, r.scheme)
+			if err != nil {
+				return err
+			}
+			if restored[resourceID(gvk, obj)] || gvk.GroupKind() == secretGroupKind || !metav1.IsControlledBy(obj, cr) || r.isRetained(obj) {
+				continue
+			}
+
+			err = r.client.Delete(ctx, obj, &client.DeleteOptions{
+				PropagationPolicy: &[]metav1.DeletionPropagation{metav1.DeletePropagationForeground}[0],
+			})
+			if err != nil && !errors.IsNotFound(err) {
+				r.recorder.Event(cr, corev1.EventTypeWarning, rollbackResourceFailed, fmt.Sprintf("Failed to delete resource %s %s, %v", gvk.Kind, obj.GetName(), err))
+				return err
+			}
+			logger.Info("Resource deleted by rollback", "namespace", obj.GetNamespace(), "name", obj.GetName(), "kind", gvk.Kind)
+			r.recorder.Event(cr, corev1.EventTypeNormal, rollbackResourceSuccess, fmt.Sprintf("Successfully deleted resource %s %s", gvk.Kind, obj.GetName()))
+		}
+	}
+
+	return nil
+}
+
+func resourceID(gvk schema.GroupVersionKind, obj metav1.Object) string {
+	return gvk.GroupKind().String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,300 @@
+package reconciler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Upgrade deadline", func() {
+	const (
+		prevVersion = "v1.0.0"
+		newVersion  = "v1.1.0"
+	)
+
+	newConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "new-config", Namespace: testcr.Namespace}}
+	snapshot := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-lifecycle-snapshot", Namespace: testcr.Namespace}}
+
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	// startUpgrade deploys the previous version and starts the upgrade to the new one, which never becomes ready
+	startUpgrade := func(action reconciler.UpgradeDeadlineAction) *args {
+		args := createArgs(prevVersion)
+		crManager := &versionedCrManager{version: prevVersion}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithUpgradeDeadline(time.Minute, action).
+			WithSnapshotNamespace(testcr.Namespace)
+
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		setDeploymentsDegraded(args)
+
+		crManager.version = newVersion
+		args.version = newVersion
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseUpgrading))
+		Expect(deploymentVersion(args)).To(Equal(newVersion))
+		_, err := getObject(args.client, newConfigMap)
+		Expect(err).ToNot(HaveOccurred())
+		return args
+	}
+
+	expireUpgrade := func(args *args) {
+		expired := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+		args.config.Status.PhaseTransitionTime = &expired
+		Expect(args.client.Status().Update(context.TODO(), args.config)).To(Succeed())
+	}
+
+	It("should requeue until the deadline", func() {
+		args := startUpgrade(reconciler.UpgradeDeadlineFail)
+
+		result, err := args.reconciler.ReconcileContext(context.TODO(), reconcileRequest(args.config.Name), newVersion, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+	})
+
+	It("should fail upgrade after the deadline", func() {
+		args := startUpgrade(reconciler.UpgradeDeadlineFail)
+		drainEvents(args.recorder)
+		expireUpgrade(args)
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		degraded := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal("UpgradeTimeout"))
+		Expect(degraded.Message).To(Equal("Upgrade from version v1.0.0 to v1.1.0 did not complete within 1m0s"))
+		Expect(deploymentVersion(args)).To(Equal(newVersion))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning UpgradeTimeout " + degraded.Message))
+
+		// the failed upgrade isn't retried by the same operator version
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+	})
+
+	It("should report upgrade after the deadline and let it go on", func() {
+		args := startUpgrade(reconciler.UpgradeDeadlineReport)
+		drainEvents(args.recorder)
+		expireUpgrade(args)
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseUpgrading))
+		degraded := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionDegraded)
+		Expect(degraded.Status).To(Equal(corev1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(reconciler.UpgradeTimeoutReason))
+		Expect(degraded.Message).To(Equal("Upgrade from version v1.0.0 to v1.1.0 did not complete within 1m0s"))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning UpgradeTimeout " + degraded.Message))
+
+		doReconcile(args)
+		Expect(drainEvents(args.recorder)).ToNot(ContainElement(HavePrefix("Warning UpgradeTimeout")))
+
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(v1.IsStatusConditionFalse(args.config.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should roll back upgrade after the deadline", func() {
+		args := startUpgrade(reconciler.UpgradeDeadlineRollback)
+		storedSnapshot, err := getObject(args.client, snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(storedSnapshot.(*corev1.Secret).Data).To(HaveKeyWithValue("version", []byte(prevVersion)))
+		drainEvents(args.recorder)
+		expireUpgrade(args)
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		Expect(args.config.Status.ObservedVersion).To(Equal(prevVersion))
+		degraded := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal("UpgradeRolledBack"))
+		Expect(deploymentVersion(args)).To(Equal(prevVersion))
+		_, err = getObject(args.client, newConfigMap)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(drainEvents(args.recorder)).To(ContainElements(
+			"Warning UpgradeRollbackStarted Upgrade from version v1.0.0 to v1.1.0 did not complete within 1m0s, rolling back to v1.0.0",
+			"Normal RollbackResourceSuccess Successfully rolled back resource Deployment "+testcr.OperatorDeploymentName,
+			"Normal RollbackResourceSuccess Successfully deleted resource ConfigMap new-config",
+			"Warning UpgradeRolledBack Upgrade from version v1.0.0 to v1.1.0 did not complete within 1m0s, rolled back to v1.0.0",
+		))
+
+		// the rolled back resources aren't upgraded again by the same operator version
+		doReconcile(args)
+		Expect(deploymentVersion(args)).To(Equal(prevVersion))
+	})
+
+	It("should leave the managed secrets out of the snapshot", func() {
+		args := createArgs(prevVersion)
+		crManager := &extraResourcesCrManager{extra: func() []client.Object {
+			return []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: testcr.Namespace},
+				Data:       map[string][]byte{"password": []byte("secret")},
+			}}
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithUpgradeDeadline(time.Minute, reconciler.UpgradeDeadlineRollback).
+			WithSnapshotNamespace(testcr.Namespace)
+
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		storedSnapshot, err := getObject(args.client, snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		reader, err := gzip.NewReader(bytes.NewReader(storedSnapshot.(*corev1.Secret).Data["objects"]))
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		var objects []map[string]interface{}
+		Expect(json.Unmarshal(data, &objects)).To(Succeed())
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0]).To(HaveKeyWithValue("kind", "Deployment"))
+	})
+
+	It("should refresh the snapshot when the deployed resources change", func() {
+		snapshotWrites := 0
+		args := createArgs(prevVersion)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if obj.GetName() == snapshot.Name {
+					snapshotWrites++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		extraConfigMaps := []string{"first"}
+		crManager := &extraResourcesCrManager{extra: func() []client.Object {
+			var objs []client.Object
+			for _, name := range extraConfigMaps {
+				objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testcr.Namespace}})
+			}
+			return objs
+		}}
+		args.reconciler = createReconcilerWithCrManager(crManager, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithUpgradeDeadline(time.Minute, reconciler.UpgradeDeadlineRollback).
+			WithSnapshotNamespace(testcr.Namespace)
+
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(snapshotObjectNames(args, snapshot)).To(ConsistOf(testcr.OperatorDeploymentName, "first"))
+
+		snapshotWrites = 0
+		doReconcile(args)
+		Expect(snapshotWrites).To(BeZero())
+
+		extraConfigMaps = append(extraConfigMaps, "second")
+		doReconcile(args)
+		Expect(snapshotWrites).To(Equal(1))
+		Expect(snapshotObjectNames(args, snapshot)).To(ConsistOf(testcr.OperatorDeploymentName, "first", "second"))
+	})
+
+	It("should not fail the reconcile when the snapshot can't be stored", func() {
+		args := createArgs(prevVersion)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == snapshot.Name {
+					return fmt.Errorf("refused")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		args.reconciler = createReconcilerWithCrManager(&versionedCrManager{version: prevVersion}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithUpgradeDeadline(time.Minute, reconciler.UpgradeDeadlineRollback).
+			WithSnapshotNamespace(testcr.Namespace)
+
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(drainEvents(args.recorder)).To(ContainElement("Warning SnapshotFailed Failed to store snapshot of managed resources: refused"))
+	})
+
+	It("should fail upgrade when there is no snapshot to roll back to", func() {
+		args := startUpgrade(reconciler.UpgradeDeadlineRollback)
+		storedSnapshot, err := getObject(args.client, snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.client.Delete(context.TODO(), storedSnapshot)).To(Succeed())
+		expireUpgrade(args)
+
+		doReconcile(args)
+
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		degraded := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal("UpgradeRollbackFailed"))
+		Expect(deploymentVersion(args)).To(Equal(newVersion))
+	})
+})
+
+// versionedCrManager manages resources that differ between operator versions
+type versionedCrManager struct {
+	testcr.ConfigCrManager
+	version string
+}
+
+func (m *versionedCrManager) GetAllResources(cr client.Object) ([]client.Object, error) {
+	resources, err := m.ConfigCrManager.GetAllResources(cr)
+	if err != nil {
+		return nil, err
+	}
+	deployment := resources[0].(*appsv1.Deployment)
+	deployment.Spec.Template.Spec.Containers[0].Env[0].Value = m.version
+	if m.version != "v1.0.0" {
+		resources = append(resources, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "new-config", Namespace: testcr.Namespace}})
+	}
+	return resources, nil
+}
+
+func (m *versionedCrManager) GetDependantResourcesListObjects() []client.ObjectList {
+	return append(m.ConfigCrManager.GetDependantResourcesListObjects(), &corev1.ConfigMapList{})
+}
+
+func deploymentVersion(args *args) string {
+	deployment, err := getDeployment(args.client, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}})
+	Expect(err).ToNot(HaveOccurred())
+	return deployment.Spec.Template.Spec.Containers[0].Env[0].Value
+}
+
+// snapshotObjectNames returns the names of the objects kept in the snapshot
+func snapshotObjectNames(args *args, snapshot client.Object) []string {
+	storedSnapshot, err := getObject(args.client, snapshot)
+	Expect(err).ToNot(HaveOccurred())
+	reader, err := gzip.NewReader(bytes.NewReader(storedSnapshot.(*corev1.Secret).Data["objects"]))
+	Expect(err).ToNot(HaveOccurred())
+	data, err := io.ReadAll(reader)
+	Expect(err).ToNot(HaveOccurred())
+	var objects []map[string]interface{}
+	Expect(json.Unmarshal(data, &objects)).To(Succeed())
+	var names []string
+	for _, object := range objects {
+		names = append(names, object["metadata"].(map[string]interface{})["name"].(string))
+	}
+	return names
+}
@@ -0,0 +1,210 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+// statusBatchKey is the context key of the status writes of the reconciled CR
+type statusBatchKey struct{}
+
+// statusBatch defers the status writes of a reconcile pass to a single one at its end
+type statusBatch struct {
+	// cr is the reconciled CR, status writes of other objects aren't deferred
+	cr client.Object
+	// written is the CR as last written to or read from the cluster, the base of the merge patches
+	written client.Object
+	// pending tells whether the status of cr has to be written
+	pending bool
+	// relatedObjects are the related objects to be added to the status of cr
+	relatedObjects []corev1.ObjectReference
+	// recovery is the Error phase cr tentatively left, until the reconcile pass succeeds
+	recovery *errorRecovery
+}
+
+// withStatusBatch returns a context deferring the status writes of the cr to flushStatus
+func (r *Reconciler) withStatusBatch(ctx context.Context, cr client.Object) context.Context {
+	return context.WithValue(ctx, statusBatchKey{}, &statusBatch{
+		cr:      cr,
+		written: cr.DeepCopyObject().(client.Object),
+	})
+}
+
+// statusBatch returns the status writes of the cr deferred by ctx, nil if they aren't
+func (r *Reconciler) statusBatch(ctx context.Context, cr client.Object) *statusBatch {
+	batch, ok := ctx.Value(statusBatchKey{}).(*statusBatch)
+	if !ok || batch.cr != cr {
+		return nil
+	}
+	return batch
+}
+
+// flushStatus writes the status of the cr if any of the deferred writes changed it
+func (r *Reconciler) flushStatus(ctx context.Context, cr client.Object) error {
+	batch := r.statusBatch(ctx, cr)
+	if batch == nil || !batch.pending {
+		return nil
+	}
+	if !r.subresourceEnabled {
+		return r.CrUpdateContext(ctx, cr)
+	}
+
+	if err := r.patchCr(ctx, cr, batch.written, true); err != nil {
+		return err
+	}
+	batch.written = cr.DeepCopyObject().(client.Object)
+	batch.pending = false
+	return nil
+}
+
+// patchCr writes the changes of the cr since base with a merge patch, to the status subresource if status is set.
+// Status patches don't carry the resource version, so that concurrent writes of other fields don't conflict with them;
+// conflicts reported anyway are retried. Other patches are guarded by it, since a merge patch replaces lists such as the finalizers as a whole; on conflict,
+// the finalizers added to and removed from the cr since base are applied to its refetched state along with its status,
+// which is written again.
+func (r *Reconciler) patchCr(ctx context.Context, cr client.Object, base client.Object, status bool) error {
+	r.stampConditionGenerations(ctx, cr, base)
+	r.syncMetaStatus(ctx, cr)
+	base = base.DeepCopyObject().(client.Object)
+	// the patch is refused unless the cr is the current one
+	base.SetResourceVersion(cr.GetResourceVersion())
+	if status {
+		patch := client.MergeFrom(base)
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			return r.client.Status().Patch(ctx, cr, patch)
+		})
+	}
+
+	added, removed := finalizerChanges(base, cr)
+	desired := cr.DeepCopyObject().(client.Object)
+	attempts := 0
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if attempts++; attempts > 1 {
+			base = sdk.NewDefaultInstance(cr)
+			if err := r.client.Get(ctx, client.ObjectKeyFromObject(cr), base); err != nil {
+				return err
+			}
+			if err := setWithStatus(cr, base, desired); err != nil {
+				return err
+			}
+			for _, finalizer := range added {
+				controllerutil.AddFinalizer(cr, finalizer)
+			}
+			for _, finalizer := range removed {
+				controllerutil.RemoveFinalizer(cr, finalizer)
+			}
+		}
+		return r.client.Patch(ctx, cr, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+// finalizerChanges returns the finalizers added to and removed from obj since base
+func finalizerChanges(base, obj client.Object) (added, removed []string) {
+	for _, finalizer := range obj.GetFinalizers() {
+		if !controllerutil.ContainsFinalizer(base, finalizer) {
+			added = append(added, finalizer)
+		}
+	}
+	for _, finalizer := range base.GetFinalizers() {
+		if !controllerutil.ContainsFinalizer(obj, finalizer) {
+			removed = append(removed, finalizer)
+		}
+	}
+	return added, removed
+}
+
+// patchCrLocked writes the changes of the cr since its state in the cluster, for which there is no known base, with a
+// merge patch guarded by its resource version, so that a stale copy can't revert the concurrent changes it lacks. A
+// stale status write is retried with the status of the cr applied to its refetched state, to the status subresource
+// if enabled; other stale writes fail with a conflict.
+func (r *Reconciler) patchCrLocked(ctx context.Context, cr client.Object, statusOnly bool) error {
+	r.syncMetaStatus(ctx, cr)
+	desired := cr.DeepCopyObject().(client.Object)
+	attempts := 0
+	write := func() error {
+		base := sdk.NewDefaultInstance(cr)
+		if err := r.client.Get(ctx, client.ObjectKeyFromObject(cr), base); err != nil {
+			return err
+		}
+		if attempts++; attempts > 1 {
+			if err := setWithStatus(cr, base, desired); err != nil {
+				return err
+			}
+		}
+		r.stampConditionGenerations(ctx, cr, base)
+		r.syncMetaStatus(ctx, cr)
+		// the patch is refused unless the cr is the current one
+		base.SetResourceVersion(cr.GetResourceVersion())
+		patch := client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})
+		if statusOnly && r.subresourceEnabled {
+			return r.client.Status().Patch(ctx, cr, patch)
+		}
+		return r.client.Patch(ctx, cr, patch)
+	}
+	if !statusOnly {
+		return write()
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, write)
+}
+
+// setWithStatus sets cr to obj with the status of desired
+func setWithStatus(cr, obj, desired client.Object) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	desiredContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return err
+	}
+	delete(content, "status")
+	if status, ok := desiredContent["status"]; ok {
+		content["status"] = status
+	}
+
+	result := sdk.NewDefaultInstance(cr)
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(content, result); err != nil {
+		return err
+	}
+	reflect.ValueOf(cr).Elem().Set(reflect.ValueOf(result).Elem())
+	return nil
+}
+
+// stampConditionGenerations records the generation of the cr for its conditions changed since base, the ones left
+// unchanged keep the generation they were last changed for
+func (r *Reconciler) stampConditionGenerations(ctx context.Context, cr, base client.Object) {
+	status := r.status(ctx, cr)
+	if status == nil {
+		return
+	}
+	var previous []conditions.Condition
+	var previousGenerations map[string]int64
+	if baseStatus := r.storedStatus(base); baseStatus != nil {
+		previous = baseStatus.Conditions
+		previousGenerations = baseStatus.ConditionGenerations
+	}
+
+	generations := make(map[string]int64, len(status.Conditions))
+	for _, condition := range status.Conditions {
+		old := conditions.FindStatusCondition(previous, condition.Type)
+		generation, known := previousGenerations[string(condition.Type)]
+		if old == nil || !known || old.Status != condition.Status || old.Reason != condition.Reason ||
+			old.Message != condition.Message {
+			generation = cr.GetGeneration()
+		}
+		generations[string(condition.Type)] = generation
+	}
+	if len(generations) == 0 {
+		generations = nil
+	}
+	status.ConditionGenerations = generations
+}
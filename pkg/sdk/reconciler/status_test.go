@@ -0,0 +1,267 @@
+package reconciler_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("CR writes", func() {
+	// crWrites counts the writes of the CR, by kind of write
+	var crWrites map[string]int
+	var conflicts int
+	// concurrentChange changes the CR in the cluster right before the next patch of the CR
+	var concurrentChange func(c client.Client)
+
+	BeforeEach(func() {
+		crWrites = map[string]int{}
+		conflicts = 0
+		concurrentChange = nil
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	createCountingArgs := func() *args {
+		args := createArgs(version)
+		args.client = interceptor.NewClient(args.client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*testcr.Config); ok {
+					crWrites["update"]++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*testcr.Config); ok {
+					crWrites["patch"]++
+					if change := concurrentChange; change != nil {
+						concurrentChange = nil
+						change(c)
+					}
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if _, ok := obj.(*testcr.Config); ok {
+					crWrites["status update"]++
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				if _, ok := obj.(*testcr.Config); ok {
+					if conflicts > 0 {
+						conflicts--
+						return errors.NewConflict(schema.GroupResource{Resource: "configs"}, obj.GetName(), nil)
+					}
+					crWrites["status patch"]++
+				}
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		})
+		args.reconciler = createReconcilerWithCrManager(&testcr.ConfigCrManager{}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		return args
+	}
+
+	It("should write the status once per reconcile", func() {
+		args := createCountingArgs()
+
+		doReconcile(args)
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		Expect(args.config.Status.OperatorVersion).To(Equal(version))
+		Expect(args.config.GetFinalizers()).To(ContainElement(finalizerName))
+		Expect(crWrites).To(Equal(map[string]int{"patch": 1, "status patch": 1}))
+
+		crWrites = map[string]int{}
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+		Expect(crWrites).To(Equal(map[string]int{"status patch": 1}))
+
+		crWrites = map[string]int{}
+		doReconcile(args)
+		Expect(crWrites).To(BeEmpty())
+	})
+
+	It("should write the status before removing the finalizer", func() {
+		args := createCountingArgs()
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		Expect(args.client.Delete(context.TODO(), args.config)).To(Succeed())
+		crWrites = map[string]int{}
+		doReconcileExpectDelete(args)
+		Expect(crWrites).To(Equal(map[string]int{"status patch": 1, "patch": 1}))
+	})
+
+	It("should retry conflicting status writes", func() {
+		args := createCountingArgs()
+		conflicts = 2
+
+		doReconcile(args)
+		Expect(conflicts).To(BeZero())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeploying))
+		Expect(crWrites["status patch"]).To(Equal(1))
+	})
+
+	It("should keep concurrent changes of the CR", func() {
+		args := createCountingArgs()
+		doReconcile(args)
+
+		stale := args.config.DeepCopy()
+		args.config.Labels["concurrent"] = "true"
+		Expect(args.client.Update(context.TODO(), args.config)).To(Succeed())
+
+		// the status is written from the stale copy read by the reconcile pass
+		conditions.RemoveStatusCondition(&stale.Status.Conditions, conditions.ConditionAvailable)
+		Expect(args.reconciler.CrUpdateStatusContext(context.TODO(), sdkapi.PhaseError, stale)).To(Succeed())
+
+		config, err := getConfig(args.client, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Labels).To(HaveKeyWithValue("concurrent", "true"))
+		Expect(config.Status.Phase).To(Equal(sdkapi.PhaseError))
+		Expect(conditions.FindStatusCondition(config.Status.Conditions, conditions.ConditionAvailable)).To(BeNil())
+	})
+
+	// changeFinalizers sets the finalizers of the CR in the cluster
+	changeFinalizers := func(c client.Client, args *args, change func(config *testcr.Config)) {
+		config, err := getConfig(c, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		change(config)
+		Expect(c.Update(context.TODO(), config)).To(Succeed())
+	}
+
+	It("should keep the finalizers added concurrently", func() {
+		args := createCountingArgs()
+		concurrentChange = func(c client.Client) {
+			changeFinalizers(c, args, func(config *testcr.Config) {
+				controllerutil.AddFinalizer(config, "other.io/finalizer")
+			})
+		}
+
+		doReconcile(args)
+		Expect(concurrentChange).To(BeNil())
+		Expect(args.config.GetFinalizers()).To(ConsistOf(finalizerName, "other.io/finalizer"))
+	})
+
+	It("should not restore the finalizers removed concurrently", func() {
+		args := createCountingArgs()
+		doReconcile(args)
+		changeFinalizers(args.client, args, func(config *testcr.Config) {
+			controllerutil.AddFinalizer(config, "other.io/finalizer")
+		})
+
+		Expect(args.client.Delete(context.TODO(), args.config)).To(Succeed())
+		concurrentChange = func(c client.Client) {
+			changeFinalizers(c, args, func(config *testcr.Config) {
+				controllerutil.RemoveFinalizer(config, "other.io/finalizer")
+			})
+		}
+		doReconcileExpectDelete(args)
+		Expect(concurrentChange).To(BeNil())
+	})
+
+	It("should refuse to write a stale copy of the CR", func() {
+		args := createCountingArgs()
+		doReconcile(args)
+
+		stale := args.config.DeepCopy()
+		args.config.Labels["concurrent"] = "true"
+		Expect(args.client.Update(context.TODO(), args.config)).To(Succeed())
+
+		stale.Labels["stale"] = "true"
+		err := args.reconciler.CrUpdateContext(context.TODO(), stale)
+		Expect(errors.IsConflict(err)).To(BeTrue())
+
+		config, err := getConfig(args.client, args.config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Labels).To(HaveKeyWithValue("concurrent", "true"))
+		Expect(config.Labels).ToNot(HaveKey("stale"))
+	})
+})
+
+var _ = Describe("Observed generation", func() {
+	var refuse bool
+
+	BeforeEach(func() {
+		refuse = false
+		invokeCallbacks = func(_ interface{}, s callbacks.ReconcileState, _ client.Object, _ client.Object) error {
+			if refuse && s == callbacks.ReconcileStatePostRead {
+				return fmt.Errorf("refused")
+			}
+			return nil
+		}
+	})
+
+	// changeSpec changes the spec of the CR in the cluster, which bumps its generation
+	changeSpec := func(args *args, policy corev1.PullPolicy) {
+		args.config.Spec.ImagePullPolicy = policy
+		args.config.Generation++
+		Expect(args.client.Update(context.TODO(), args.config)).To(Succeed())
+	}
+
+	It("should record the generation of the reconciled CR", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		Expect(args.config.Status.ObservedGeneration).To(Equal(args.config.Generation))
+
+		changeSpec(args, corev1.PullAlways)
+		doReconcile(args)
+		Expect(args.config.Status.ObservedGeneration).To(Equal(args.config.Generation))
+	})
+
+	It("should keep the generation last reconciled successfully", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		observed := args.config.Status.ObservedGeneration
+
+		refuse = true
+		changeSpec(args, corev1.PullAlways)
+		doReconcileError(args)
+		Expect(args.config.Status.ObservedGeneration).To(Equal(observed))
+		Expect(args.config.Generation).ToNot(Equal(observed))
+
+		refuse = false
+		doReconcile(args)
+		Expect(args.config.Status.ObservedGeneration).To(Equal(args.config.Generation))
+	})
+
+	It("should record the generation each condition was last changed for", func() {
+		args := createArgs(version)
+		doReconcile(args)
+		deploying := args.config.Status.DeepCopy()
+
+		changeSpec(args, corev1.PullAlways)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		ready := args.config.Generation
+		for _, condition := range args.config.Status.Conditions {
+			old := conditions.FindStatusCondition(deploying.Conditions, condition.Type)
+			if old.Status == condition.Status && old.Reason == condition.Reason && old.Message == condition.Message {
+				Expect(args.config.Status.ConditionGenerations).To(
+					HaveKeyWithValue(string(condition.Type), deploying.ConditionGenerations[string(condition.Type)]))
+			} else {
+				Expect(args.config.Status.ConditionGenerations).To(HaveKeyWithValue(string(condition.Type), ready))
+			}
+		}
+		Expect(args.config.Status.ConditionGenerations).To(HaveKeyWithValue(string(conditions.ConditionAvailable), ready))
+
+		available := args.config.Status.DeepCopy()
+		changeSpec(args, corev1.PullIfNotPresent)
+		doReconcile(args)
+		Expect(args.config.Status.ObservedGeneration).To(Equal(args.config.Generation))
+		Expect(args.config.Status.ConditionGenerations).To(Equal(available.ConditionGenerations))
+	})
+})
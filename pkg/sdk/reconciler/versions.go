@@ -1,49 +1,83 @@
 package reconciler
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/blang/semver"
 )
 
+// DowngradePolicy decides whether the operator reconciles the resources deployed by a newer version
+type DowngradePolicy string
+
+const (
+	// DowngradeRefuse refuses to reconcile after any downgrade
+	DowngradeRefuse DowngradePolicy = "refuse"
+	// DowngradeAllowWithDowngradePath runs the downgrade hooks and reconciles the resources after any downgrade
+	DowngradeAllowWithDowngradePath DowngradePolicy = "allow-with-downgrade-path"
+	// DowngradeAllowWithinPatch reconciles the resources after a downgrade to a lower patch version only
+	DowngradeAllowWithinPatch DowngradePolicy = "allow-within-patch"
+)
+
+// DowngradeError reports a downgrade refused by the DowngradePolicy
+type DowngradeError struct {
+	CurrentVersion string
+	TargetVersion  string
+	Policy         DowngradePolicy
+}
+
+func (e *DowngradeError) Error() string {
+	return "operator downgraded, will not reconcile"
+}
+
 // ShouldTakeUpdatePath checks whether upgrade-type reconciliation should be executed. Returns error in case of downgrade
 func ShouldTakeUpdatePath(targetVersion, currentVersion string, deploying bool) (bool, error) {
+	update, _, err := ShouldTakeUpdatePathWithPolicy(targetVersion, currentVersion, deploying, DowngradeRefuse)
+	return update, err
+}
+
+// ShouldTakeUpdatePathWithPolicy checks whether upgrade-type reconciliation should be executed and whether it is a
+// downgrade. Returns DowngradeError in case of a downgrade the policy refuses.
+func ShouldTakeUpdatePathWithPolicy(targetVersion, currentVersion string, deploying bool, policy DowngradePolicy) (bool, bool, error) {
 
 	if deploying {
-		return false, nil
+		return false, false, nil
 	}
 	if targetVersion == currentVersion {
-		return false, nil
+		return false, false, nil
 	}
 
 	// if no current version, then we can't perform semantic version comparison. But since the target version is not
 	// empty, and since we are not deploying, then we're upgrading
 	if currentVersion == "" {
-		return true, nil
+		return true, false, nil
 	}
 
 	// semver doesn't like the 'v' prefix
-	targetVersion = strings.TrimPrefix(targetVersion, "v")
-	currentVersion = strings.TrimPrefix(currentVersion, "v")
+	target, targetErr := semver.Make(strings.TrimPrefix(targetVersion, "v"))
+	current, currentErr := semver.Make(strings.TrimPrefix(currentVersion, "v"))
 
 	// our default position is that this is an update.
 	// So if the target and current version do not
 	// adhere to the semver spec, we assume by default the
 	// update path is the correct path.
-	shouldTakeUpdatePath := true
-	target, err := semver.Make(targetVersion)
-	if err == nil {
-		current, err := semver.Make(currentVersion)
-		if err == nil {
-			if target.Compare(current) < 0 {
-				err := fmt.Errorf("operator downgraded, will not reconcile")
-				return false, err
-			} else if target.Compare(current) == 0 {
-				shouldTakeUpdatePath = false
-			}
-		}
+	if targetErr != nil || currentErr != nil {
+		return true, false, nil
 	}
 
-	return shouldTakeUpdatePath, nil
+	switch target.Compare(current) {
+	case 0:
+		return false, false, nil
+	case 1:
+		return true, false, nil
+	}
+
+	switch policy {
+	case DowngradeAllowWithDowngradePath:
+		return true, true, nil
+	case DowngradeAllowWithinPatch:
+		if target.Major == current.Major && target.Minor == current.Minor {
+			return true, true, nil
+		}
+	}
+	return false, true, &DowngradeError{CurrentVersion: currentVersion, TargetVersion: targetVersion, Policy: policy}
 }
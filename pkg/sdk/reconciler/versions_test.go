@@ -57,3 +57,36 @@ var _ = Describe("Version downgrade", func() {
 		Expect(upgrade).To(BeFalse())
 	})
 })
+
+var _ = Describe("Version downgrade with policy", func() {
+	DescribeTable("should be allowed", func(currentVersion, targetVersion string, policy reconciler.DowngradePolicy) {
+		upgrade, downgrade, err := reconciler.ShouldTakeUpdatePathWithPolicy(targetVersion, currentVersion, false, policy)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upgrade).To(BeTrue())
+		Expect(downgrade).To(BeTrue())
+	},
+		Entry("with downgrade path", "1.0.0", "0.9.5", reconciler.DowngradeAllowWithDowngradePath),
+		Entry("within patch", "v0.9.5", "v0.9.4", reconciler.DowngradeAllowWithinPatch),
+	)
+
+	DescribeTable("should be refused", func(currentVersion, targetVersion string, policy reconciler.DowngradePolicy) {
+		upgrade, downgrade, err := reconciler.ShouldTakeUpdatePathWithPolicy(targetVersion, currentVersion, false, policy)
+
+		Expect(err).To(BeAssignableToTypeOf(&reconciler.DowngradeError{}))
+		Expect(upgrade).To(BeFalse())
+		Expect(downgrade).To(BeTrue())
+	},
+		Entry("by refuse policy", "0.9.5", "0.9.4", reconciler.DowngradeRefuse),
+		Entry("across minor versions", "0.9.5", "0.8.5", reconciler.DowngradeAllowWithinPatch),
+		Entry("across major versions", "1.0.0", "0.9.5", reconciler.DowngradeAllowWithinPatch),
+	)
+
+	It("should not report upgrade as downgrade", func() {
+		upgrade, downgrade, err := reconciler.ShouldTakeUpdatePathWithPolicy("0.9.5", "0.9.4", false, reconciler.DowngradeAllowWithinPatch)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upgrade).To(BeTrue())
+		Expect(downgrade).To(BeFalse())
+	})
+})
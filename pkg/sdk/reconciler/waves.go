@@ -0,0 +1,153 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	conditions "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk"
+)
+
+const (
+	// ApplyWaveAnnotation is the name of the annotation (under the annotation prefix) assigning a managed resource to
+	// an apply wave; its value is an integer, resources without it belong to wave 0
+	ApplyWaveAnnotation = "apply-wave"
+
+	applyWaveNotReady = "ApplyWaveNotReady"
+	applyWavesReady   = "ApplyWavesReady"
+
+	applyWaveRequeueInterval = 5 * time.Second
+)
+
+// ApplyWavesCrManager may be implemented by a CrManager that declares the order in which the managed resources are
+// applied. A wave is applied only after every resource of the previous waves is ready.
+type ApplyWavesCrManager interface {
+	// GetResourceWaves provides all resources managed by the cr grouped into apply waves, in apply order
+	GetResourceWaves(ctx context.Context, cr client.Object) ([][]client.Object, error)
+}
+
+// applyWave is a group of managed resources applied together
+type applyWave struct {
+	number    int
+	resources []client.Object
+}
+
+// getResourceWaves groups the managed resources into apply waves, either as declared by the CrManager or according to
+// the apply wave annotation
+func (r *Reconciler) getResourceWaves(ctx context.Context, cr client.Object) ([]applyWave, error) {
+	if _, ok := r.crManager.(ApplyWavesCrManager); ok {
+		declared, err := r.desiredWaves(ctx, cr)
+		if err != nil {
+			return nil, err
+		}
+		waves := make([]applyWave, len(declared))
+		for i, resources := range declared {
+			waves[i] = applyWave{number: i, resources: resources}
+		}
+		return waves, nil
+	}
+
+	resources, err := r.getAllResources(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := map[int]*applyWave{}
+	var waves []*applyWave
+	for _, resource := range resources {
+		number := 0
+		if v, ok := resource.GetAnnotations()[r.annotation(ApplyWaveAnnotation)]; ok {
+			if number, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid apply wave %q of %T %s: %v", v, resource, resource.GetName(), err)
+			}
+		}
+		wave, ok := byNumber[number]
+		if !ok {
+			wave = &applyWave{number: number}
+			byNumber[number] = wave
+			waves = append(waves, wave)
+		}
+		wave.resources = append(wave.resources, resource)
+	}
+
+	sort.Slice(waves, func(i, j int) bool {
+		return waves[i].number < waves[j].number
+	})
+
+	result := make([]applyWave, len(waves))
+	for i, wave := range waves {
+		result[i] = *wave
+	}
+	return result, nil
+}
+
+// findNotReady returns the first resource of the wave that is not ready yet, nil if all are
+func (r *Reconciler) findNotReady(ctx context.Context, cr client.Object, wave applyWave) (client.Object, error) {
+	for _, resource := range wave.resources {
+		ready, err := r.isResourceReady(ctx, cr, resource)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			return resource, nil
+		}
+	}
+	return nil, nil
+}
+
+// gatesApplyWaves returns whether the waves of cr are applied only after the previous ones are ready, which is the case
+// while it is deployed or upgraded. Once deployed, all waves are applied and an unready resource is reported by
+// CheckDegraded instead.
+func (r *Reconciler) gatesApplyWaves(ctx context.Context, cr client.Object) bool {
+	status := r.status(ctx, cr)
+	return status.Phase != sdkapi.PhaseDeployed || sdk.IsUpgrading(status)
+}
+
+// markWaitingForApplyWave reports the apply wave blocking the progress in the CR status
+func (r *Reconciler) markWaitingForApplyWave(ctx context.Context, logger logr.Logger, cr client.Object, wave applyWave, notReady client.Object) error {
+	message := fmt.Sprintf("Waiting for apply wave %d to become ready: %T %s/%s is not ready", wave.number, notReady, notReady.GetNamespace(), notReady.GetName())
+	logger.Info("Apply wave not ready", "wave", wave.number, "namespace", notReady.GetNamespace(), "name", notReady.GetName(), "type", fmt.Sprintf("%T", notReady))
+
+	status := r.status(ctx, cr)
+	progressing := conditions.FindStatusCondition(status.Conditions, conditions.ConditionProgressing)
+	if progressing != nil && progressing.Status == corev1.ConditionTrue && progressing.Reason == applyWaveNotReady && progressing.Message == message {
+		return nil
+	}
+
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    conditions.ConditionProgressing,
+		Status:  corev1.ConditionTrue,
+		Reason:  applyWaveNotReady,
+		Message: message,
+	})
+	r.recorder.Event(cr, corev1.EventTypeNormal, applyWaveNotReady, message)
+	return r.CrUpdateStatusContext(ctx, status.Phase, cr)
+}
+
+// clearWaitingForApplyWave resets the Progressing condition set by markWaitingForApplyWave
+func (r *Reconciler) clearWaitingForApplyWave(ctx context.Context, cr client.Object) {
+	status := r.status(ctx, cr)
+	progressing := conditions.FindStatusCondition(status.Conditions, conditions.ConditionProgressing)
+	if progressing == nil || progressing.Reason != applyWaveNotReady {
+		return
+	}
+
+	progressingStatus := corev1.ConditionTrue
+	if status.Phase == sdkapi.PhaseDeployed {
+		progressingStatus = corev1.ConditionFalse
+	}
+	conditions.SetStatusCondition(&status.Conditions, conditions.Condition{
+		Type:    conditions.ConditionProgressing,
+		Status:  progressingStatus,
+		Reason:  applyWavesReady,
+		Message: "All apply waves are ready",
+	})
+}
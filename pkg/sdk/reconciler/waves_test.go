@@ -0,0 +1,174 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift/custom-resource-status/conditions/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/callbacks"
+	"kubevirt.io/controller-lifecycle-operator-sdk/pkg/sdk/reconciler"
+	testcr "kubevirt.io/controller-lifecycle-operator-sdk/tests/cr"
+)
+
+var _ = Describe("Apply waves", func() {
+	BeforeEach(func() {
+		invokeCallbacks = func(interface{}, callbacks.ReconcileState, client.Object, client.Object) error {
+			return nil
+		}
+	})
+
+	It("should apply declared wave only after the previous one is ready", func() {
+		args := createArgs(version)
+		configMap := createWaveConfigMap(nil)
+		args.reconciler = createReconcilerWithCrManager(&declaredWavesCrManager{second: configMap}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+
+		doReconcile(args)
+
+		_, err := getObject(args.client, configMap)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		progressing := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionProgressing)
+		Expect(progressing).ToNot(BeNil())
+		Expect(progressing.Reason).To(Equal("ApplyWaveNotReady"))
+		Expect(progressing.Message).To(ContainSubstring("apply wave 0"))
+		Expect(progressing.Message).To(ContainSubstring(testcr.OperatorDeploymentName))
+
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+
+		_, err = getObject(args.client, configMap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+	})
+
+	It("should apply all waves and report degraded once deployed", func() {
+		args := createArgs(version)
+		configMap := createWaveConfigMap(nil)
+		args.reconciler = createReconcilerWithCrManager(&declaredWavesCrManager{second: configMap}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+		doReconcile(args)
+		Expect(setDeploymentsReady(args)).To(BeTrue())
+		Expect(args.config.Status.Phase).To(Equal(sdkapi.PhaseDeployed))
+
+		deployment, err := getDeployment(args.client, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testcr.OperatorDeploymentName, Namespace: testcr.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+		deployment.Status.ReadyReplicas = 0
+		Expect(args.client.Status().Update(context.TODO(), deployment)).To(Succeed())
+		configMap.Labels = map[string]string{"changed": "true"}
+
+		doReconcile(args)
+
+		stored, err := getObject(args.client, configMap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.GetLabels()).To(HaveKeyWithValue("changed", "true"))
+		Expect(v1.IsStatusConditionTrue(args.config.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+		progressing := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionProgressing)
+		Expect(progressing.Reason).ToNot(Equal("ApplyWaveNotReady"))
+	})
+
+	It("should wait for CRD to be established before applying annotated wave", func() {
+		args := createArgs(version)
+		crd := createWaveCRD()
+		configMap := createWaveConfigMap(map[string]string{reconciler.DefaultAnnotationPrefix + "/" + reconciler.ApplyWaveAnnotation: "1"})
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{configMap, crd}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController).
+			WithBuiltinReadinessCheckers(extv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+
+		doReconcile(args)
+
+		_, err := getObject(args.client, crd)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = getObject(args.client, configMap)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		progressing := v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionProgressing)
+		Expect(progressing.Message).To(ContainSubstring(crd.Name))
+
+		storedCRD, err := getObject(args.client, crd)
+		Expect(err).ToNot(HaveOccurred())
+		storedCRD.(*extv1.CustomResourceDefinition).Status.Conditions = []extv1.CustomResourceDefinitionCondition{
+			{Type: extv1.Established, Status: extv1.ConditionTrue},
+		}
+		Expect(args.client.Status().Update(context.TODO(), storedCRD)).To(Succeed())
+
+		doReconcile(args)
+
+		_, err = getObject(args.client, configMap)
+		Expect(err).ToNot(HaveOccurred())
+		progressing = v1.FindStatusCondition(args.config.Status.Conditions, v1.ConditionProgressing)
+		Expect(progressing.Reason).ToNot(Equal("ApplyWaveNotReady"))
+	})
+
+	It("should reject invalid wave annotation", func() {
+		args := createArgs(version)
+		configMap := createWaveConfigMap(map[string]string{reconciler.DefaultAnnotationPrefix + "/" + reconciler.ApplyWaveAnnotation: "first"})
+		args.reconciler = createReconcilerWithCrManager(&annotatedWavesCrManager{resources: []client.Object{configMap}}, args.client, args.client.Scheme(), args.recorder).
+			WithController(args.mockController)
+
+		doReconcileError(args)
+	})
+})
+
+type declaredWavesCrManager struct {
+	testcr.ConfigCrManager
+	second client.Object
+}
+
+func (m *declaredWavesCrManager) GetResourceWaves(_ context.Context, cr client.Object) ([][]client.Object, error) {
+	first, err := m.GetAllResources(cr)
+	if err != nil {
+		return nil, err
+	}
+	return [][]client.Object{first, {m.second.DeepCopyObject().(client.Object)}}, nil
+}
+
+type annotatedWavesCrManager struct {
+	testcr.ConfigCrManager
+	resources []client.Object
+}
+
+func (m *annotatedWavesCrManager) GetAllResources(_ client.Object) ([]client.Object, error) {
+	var result []client.Object
+	for _, resource := range m.resources {
+		result = append(result, resource.DeepCopyObject().(client.Object))
+	}
+	return result, nil
+}
+
+func createWaveConfigMap(annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "wave-config",
+			Namespace:   testcr.Namespace,
+			Annotations: annotations,
+		},
+		Data: map[string]string{"key": "value"},
+	}
+}
+
+func createWaveCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "waves.configs.test",
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "configs.test",
+			Scope: extv1.ClusterScoped,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:   "Wave",
+				Plural: "waves",
+			},
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true, Storage: true},
+			},
+		},
+	}
+}
@@ -4,59 +4,231 @@ import extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 // OperatorConfigStatus provides JSONSchemaProps for the Status struct
 func OperatorConfigStatus(statusName string) extv1.JSONSchemaProps {
-	return extv1.JSONSchemaProps{
+	properties := statusProperties()
+	properties["conditionGenerations"] = extv1.JSONSchemaProps{
+		Description: "The generation of the resource each condition was last changed for, by condition type",
 		Type:        "object",
-		Description: statusName + " defines the status of the installation",
-		Properties: map[string]extv1.JSONSchemaProps{
-			"targetVersion": {
-				Description: "The desired version of the resource",
-				Type:        "string",
+		AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+			Allows: true,
+			Schema: &extv1.JSONSchemaProps{
+				Type:   "integer",
+				Format: "int64",
 			},
-			"observedVersion": {
-				Description: "The observed version of the resource",
-				Type:        "string",
+		},
+	}
+	properties["conditions"] = extv1.JSONSchemaProps{
+		Description: "A list of current conditions of the resource",
+		Type:        "array",
+		Items: &extv1.JSONSchemaPropsOrArray{
+			Schema: &extv1.JSONSchemaProps{
+				Type:        "object",
+				Description: "Condition represents the state of the operator's reconciliation functionality.",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"lastHeartbeatTime": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"lastTransitionTime": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"message": {
+						Type: "string",
+					},
+					"reason": {
+						Type: "string",
+					},
+					"status": {
+						Type: "string",
+					},
+					"type": {
+						Description: "ConditionType is the state of the operator's reconciliation functionality.",
+						Type:        "string",
+					},
+				},
+				Required: []string{
+					"status",
+					"type",
+				},
 			},
-			"operatorVersion": {
-				Description: "The version of the resource as defined by the operator",
-				Type:        "string",
+		},
+	}
+	return extv1.JSONSchemaProps{
+		Type:        "object",
+		Description: statusName + " defines the status of the installation",
+		Properties:  properties,
+	}
+}
+
+// OperatorConfigMetaStatus provides JSONSchemaProps for the MetaStatus struct
+func OperatorConfigMetaStatus(statusName string) extv1.JSONSchemaProps {
+	properties := statusProperties()
+	mapListType := "map"
+	properties["conditions"] = extv1.JSONSchemaProps{
+		Description:  "A list of current conditions of the resource",
+		Type:         "array",
+		XListType:    &mapListType,
+		XListMapKeys: []string{"type"},
+		Items: &extv1.JSONSchemaPropsOrArray{
+			Schema: &extv1.JSONSchemaProps{
+				Type:        "object",
+				Description: "Condition contains details for one aspect of the current state of this API Resource.",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"lastTransitionTime": {
+						Description: "The last time the condition transitioned from one status to another.",
+						Type:        "string",
+						Format:      "date-time",
+					},
+					"message": {
+						Description: "A human readable message indicating details about the transition.",
+						Type:        "string",
+						MaxLength:   &[]int64{32768}[0],
+					},
+					"observedGeneration": {
+						Description: "The generation of the resource the condition was set based upon.",
+						Type:        "integer",
+						Format:      "int64",
+						Minimum:     &[]float64{0}[0],
+					},
+					"reason": {
+						Description: "A programmatic identifier indicating the reason for the condition's last transition.",
+						Type:        "string",
+						MaxLength:   &[]int64{1024}[0],
+						MinLength:   &[]int64{1}[0],
+						Pattern:     `^[A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?$`,
+					},
+					"status": {
+						Description: "Status of the condition, one of True, False, Unknown.",
+						Type:        "string",
+						Enum: []extv1.JSON{
+							{Raw: []byte(`"True"`)},
+							{Raw: []byte(`"False"`)},
+							{Raw: []byte(`"Unknown"`)},
+						},
+					},
+					"type": {
+						Description: "Type of condition in CamelCase.",
+						Type:        "string",
+						MaxLength:   &[]int64{316}[0],
+						Pattern:     `^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])$`,
+					},
+				},
+				Required: []string{
+					"lastTransitionTime",
+					"message",
+					"reason",
+					"status",
+					"type",
+				},
 			},
-			"phase": {
-				Description: "Phase is the current phase of the deployment",
-				Type:        "string",
+		},
+	}
+	return extv1.JSONSchemaProps{
+		Type:        "object",
+		Description: statusName + " defines the status of the installation",
+		Properties:  properties,
+	}
+}
+
+// statusProperties provides the JSONSchemaProps of the fields Status and MetaStatus have in common
+func statusProperties() map[string]extv1.JSONSchemaProps {
+	return map[string]extv1.JSONSchemaProps{
+		"targetVersion": {
+			Description: "The desired version of the resource",
+			Type:        "string",
+		},
+		"observedVersion": {
+			Description: "The observed version of the resource",
+			Type:        "string",
+		},
+		"operatorVersion": {
+			Description: "The version of the resource as defined by the operator",
+			Type:        "string",
+		},
+		"observedGeneration": {
+			Description: "The generation of the resource last reconciled successfully",
+			Type:        "integer",
+			Format:      "int64",
+		},
+		"phase": {
+			Description: "Phase is the current phase of the deployment",
+			Type:        "string",
+		},
+		"phaseTransitionTime": {
+			Description: "The time the resource entered the current phase",
+			Type:        "string",
+			Format:      "date-time",
+		},
+		"resources": {
+			Description: "The sync status of the objects managed by the resource, if enabled in the operator",
+			Type:        "array",
+			Items: &extv1.JSONSchemaPropsOrArray{
+				Schema: &extv1.JSONSchemaProps{
+					Type:        "object",
+					Description: "ResourceStatus represents the sync status of an object managed by the operator configuration resource",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"apiVersion": {
+							Description: "API version of the object",
+							Type:        "string",
+						},
+						"kind": {
+							Description: "Kind of the object",
+							Type:        "string",
+						},
+						"namespace": {
+							Description: "Namespace of the object, empty for cluster scoped ones",
+							Type:        "string",
+						},
+						"name": {
+							Description: "Name of the object",
+							Type:        "string",
+						},
+						"syncResult": {
+							Description: "The result of the last reconciliation of the object",
+							Type:        "string",
+						},
+						"error": {
+							Description: "The error of the last reconciliation of the object, if it failed",
+							Type:        "string",
+						},
+						"ready": {
+							Description: "Whether the object is ready",
+							Type:        "boolean",
+						},
+					},
+					Required: []string{
+						"apiVersion",
+						"kind",
+						"name",
+						"ready",
+						"syncResult",
+					},
+				},
 			},
-			"conditions": {
-				Description: "A list of current conditions of the resource",
-				Type:        "array",
-				Items: &extv1.JSONSchemaPropsOrArray{
-					Schema: &extv1.JSONSchemaProps{
-						Type:        "object",
-						Description: "Condition represents the state of the operator's reconciliation functionality.",
-						Properties: map[string]extv1.JSONSchemaProps{
-							"lastHeartbeatTime": {
-								Type:   "string",
-								Format: "date-time",
-							},
-							"lastTransitionTime": {
-								Type:   "string",
-								Format: "date-time",
-							},
-							"message": {
-								Type: "string",
-							},
-							"reason": {
-								Type: "string",
-							},
-							"status": {
-								Type: "string",
-							},
-							"type": {
-								Description: "ConditionType is the state of the operator's reconciliation functionality.",
-								Type:        "string",
-							},
+		},
+		"relatedObjects": {
+			Description: "The objects managed by the resource",
+			Type:        "array",
+			Items: &extv1.JSONSchemaPropsOrArray{
+				Schema: &extv1.JSONSchemaProps{
+					Type:        "object",
+					Description: "ObjectReference contains enough information to let you inspect or modify the referred object.",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"apiVersion": {
+							Description: "API version of the referent.",
+							Type:        "string",
+						},
+						"kind": {
+							Description: "Kind of the referent.",
+							Type:        "string",
+						},
+						"namespace": {
+							Description: "Namespace of the referent.",
+							Type:        "string",
 						},
-						Required: []string{
-							"status",
-							"type",
+						"name": {
+							Description: "Name of the referent.",
+							Type:        "string",
 						},
 					},
 				},
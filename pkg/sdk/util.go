@@ -17,19 +17,19 @@ limitations under the License.
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"reflect"
 	"strings"
 
 	jsondiff "github.com/appscode/jsonpatch"
-	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
 	"k8s.io/apimachinery/pkg/util/mergepatch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -67,67 +67,124 @@ func MergeLabelsAndAnnotations(src, dest metav1.Object) {
 	}
 }
 
+// MergeObject computes the three-way merge of desiredObj, currentObj and the last applied configuration kept in the
+// annotation of currentObj, either in the plain JSON or in the compressed form
 func MergeObject(desiredObj, currentObj client.Object, lastAppliedConfigAnnotation string) (client.Object, error) {
+	return MergeObjectWithStore(context.Background(), desiredObj, currentObj, NewAnnotationStore(lastAppliedConfigAnnotation))
+}
+
+// MergeObjectWithStore computes the three-way merge of desiredObj, currentObj and the last applied configuration of
+// currentObj kept by store. The objects are merged as unstructured maps, only the last applied configuration is
+// decoded from JSON.
+func MergeObjectWithStore(ctx context.Context, desiredObj, currentObj client.Object, store LastAppliedStore) (client.Object, error) {
 	desiredObj = desiredObj.DeepCopyObject().(client.Object)
 	desiredMetaObj := desiredObj.(metav1.Object)
 	currentMetaObj := currentObj.(metav1.Object)
 
-	v, ok := currentMetaObj.GetAnnotations()[lastAppliedConfigAnnotation]
-	if !ok {
+	lastApplied, err := store.Load(ctx, currentObj)
+	if err != nil {
+		return nil, err
+	}
+	if lastApplied == nil {
 		log.Info("Resource missing last applied config", "resource", currentMetaObj)
 	}
+	original, err := decodeJSONMap(lastApplied)
+	if err != nil {
+		return nil, err
+	}
 
-	original := []byte(v)
 	// setting the timestamp saves unnecessary updates because creation timestamp is nulled
 	desiredMetaObj.SetCreationTimestamp(currentMetaObj.GetCreationTimestamp())
-	modified, err := json.Marshal(desiredObj)
+	modified, err := toUnstructuredMap(desiredObj)
 	if err != nil {
 		return nil, err
 	}
 
-	current, err := json.Marshal(currentObj)
+	current, err := toUnstructuredMap(currentObj)
 	if err != nil {
 		return nil, err
 	}
+	identity := objectIdentity(current)
 
-	preconditions := []mergepatch.PreconditionFunc{
-		mergepatch.RequireKeyUnchanged("apiVersion"),
-		mergepatch.RequireKeyUnchanged("kind"),
-		mergepatch.RequireMetadataKeyUnchanged("name"),
+	threeWayMerge(original, modified, current)
+	if !reflect.DeepEqual(objectIdentity(current), identity) {
+		return nil, mergepatch.NewErrPreconditionFailed(current)
 	}
 
-	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current, preconditions...)
-	if err != nil {
-		return nil, err
-	}
+	return fromUnstructuredMap(current, currentObj)
+}
 
-	newCurrent, err := jsonpatch.MergePatch(current, patch)
+// MergeObjectAdditive adds the fields of desiredObj missing in currentObj, keeping the values of the fields present in
+// both objects, as well as the fields set in currentObj only. Nested objects are merged recursively, lists are kept.
+func MergeObjectAdditive(desiredObj, currentObj client.Object) (client.Object, error) {
+	desired, err := toUnstructuredMap(desiredObj)
 	if err != nil {
 		return nil, err
 	}
-
-	result := NewDefaultInstance(currentObj)
-	if err = json.Unmarshal(newCurrent, result); err != nil {
+	current, err := toUnstructuredMap(currentObj)
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	addMissingFields(desired, current)
+	return fromUnstructuredMap(current, currentObj)
 }
 
-func StripStatusFromObject(obj client.Object) (client.Object, error) {
-	modified, err := json.Marshal(obj)
+// ReplaceObject replaces the content of currentObj except its metadata with the one of desiredObj. Fields set in
+// currentObj only, including the ones defaulted by the API server, are dropped.
+func ReplaceObject(desiredObj, currentObj client.Object) (client.Object, error) {
+	desired, err := toUnstructuredMap(desiredObj)
 	if err != nil {
 		return nil, err
 	}
-	modified, err = StripStatusByte(modified)
+	current, err := toUnstructuredMap(currentObj)
 	if err != nil {
 		return nil, err
 	}
-	result := NewDefaultInstance(obj)
-	if err = json.Unmarshal(modified, result); err != nil {
-		return nil, err
+
+	for key := range current {
+		if !isObjectHeaderKey(key) {
+			delete(current, key)
+		}
+	}
+	for key, value := range desired {
+		if !isObjectHeaderKey(key) {
+			current[key] = value
+		}
 	}
+	return fromUnstructuredMap(current, currentObj)
+}
+
+func isObjectHeaderKey(key string) bool {
+	return key == "apiVersion" || key == "kind" || key == "metadata" || key == statusKey
+}
 
+func addMissingFields(src, dest map[string]interface{}) {
+	for key, srcValue := range src {
+		destValue, ok := dest[key]
+		if !ok || destValue == nil {
+			dest[key] = srcValue
+			continue
+		}
+		srcMap, srcOk := srcValue.(map[string]interface{})
+		destMap, destOk := destValue.(map[string]interface{})
+		if srcOk && destOk {
+			addMissingFields(srcMap, destMap)
+		}
+	}
+}
+
+// StripStatusFromObject returns a copy of obj without its status
+func StripStatusFromObject(obj client.Object) (client.Object, error) {
+	result := obj.DeepCopyObject().(client.Object)
+	if u, ok := result.(runtime.Unstructured); ok {
+		content := u.UnstructuredContent()
+		delete(content, statusKey)
+		delete(content, capitalStatusKey)
+		u.SetUnstructuredContent(content)
+		return result, nil
+	}
+	clearStatusField(result)
 	return result, nil
 }
 
@@ -149,13 +206,27 @@ func DeployClusterResources() bool {
 }
 
 func LogJSONDiff(logger logr.Logger, objA, objB interface{}) {
-	aBytes, _ := json.Marshal(objA)
-	bBytes, _ := json.Marshal(objB)
-	patches, _ := jsondiff.CreatePatch(aBytes, bBytes)
-	pBytes, _ := json.Marshal(patches)
+	pBytes, _ := CreateJSONPatch(objA, objB)
 	logger.Info("DIFF", "obj", objA, "patch", string(pBytes))
 }
 
+// CreateJSONPatch computes the JSON patch turning objA into objB
+func CreateJSONPatch(objA, objB interface{}) ([]byte, error) {
+	aBytes, err := json.Marshal(objA)
+	if err != nil {
+		return nil, err
+	}
+	bBytes, err := json.Marshal(objB)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := jsondiff.CreatePatch(aBytes, bBytes)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(patches)
+}
+
 func CheckDeploymentReady(deployment *appsv1.Deployment) bool {
 	desiredReplicas := deployment.Spec.Replicas
 	if desiredReplicas == nil {
@@ -170,6 +241,16 @@ func CheckDeploymentReady(deployment *appsv1.Deployment) bool {
 	return true
 }
 
+// CheckCRDEstablished checks whether the CRD has been accepted and its API is being served
+func CheckCRDEstablished(crd *extv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == extv1.Established {
+			return condition.Status == extv1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func NewDefaultInstance(obj client.Object) client.Object {
 	typ := reflect.ValueOf(obj).Elem().Type()
 	return reflect.New(typ).Interface().(client.Object)
@@ -184,6 +265,9 @@ func ContainsStringValue(values []string, value string) bool {
 	return false
 }
 
+// IsMutable checks whether obj is one of the kinds whose content the Reconciler leaves to the users by default.
+//
+// Deprecated: the Reconciler looks the merge strategy up by GVK, see reconciler.DefaultMergeStrategies.
 func IsMutable(obj client.Object) bool {
 	switch obj.(type) {
 	case *v1.ConfigMap, *v1.Secret:
@@ -17,6 +17,7 @@ limitations under the License.
 package sdk
 
 import (
+	"context"
 	"reflect"
 
 	. "github.com/onsi/ginkgo"
@@ -142,6 +143,98 @@ var _ = Describe("MergeLabelsAndAnnotations", func() {
 	})
 })
 
+var _ = Describe("Last applied stores", func() {
+	createCRD := func(preserveUnknownFields bool) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "obj"},
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group:                 "foo",
+				PreserveUnknownFields: preserveUnknownFields,
+			},
+		}
+	}
+
+	It("should merge with compressed last applied configuration", func() {
+		store := NewCompressedAnnotationStore(lastsAppliedConfigurationAnnotation, DefaultMaxLastAppliedSize)
+		current := createCRD(true)
+		Expect(store.Save(context.TODO(), current)).To(Succeed())
+		Expect(current.Annotations[lastsAppliedConfigurationAnnotation]).To(HavePrefix("gzip+base64:"))
+
+		config, err := store.Load(context.TODO(), current)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(config)).To(ContainSubstring(`"preserveUnknownFields":true`))
+
+		desired := createCRD(false)
+		desired.Spec.Group = ""
+		Expect(store.Save(context.TODO(), desired)).To(Succeed())
+
+		merged, err := MergeObject(desired, current, lastsAppliedConfigurationAnnotation)
+		Expect(err).ToNot(HaveOccurred())
+		crd := merged.(*extv1.CustomResourceDefinition)
+		Expect(crd.Spec.PreserveUnknownFields).To(BeFalse())
+		Expect(crd.Spec.Group).To(BeEmpty())
+		Expect(crd.Annotations[lastsAppliedConfigurationAnnotation]).To(Equal(desired.Annotations[lastsAppliedConfigurationAnnotation]))
+	})
+
+	It("should refuse too large compressed configuration", func() {
+		store := NewCompressedAnnotationStore(lastsAppliedConfigurationAnnotation, 16)
+		obj := createCRD(true)
+		Expect(store.Save(context.TODO(), obj)).ToNot(Succeed())
+		Expect(obj.Annotations).ToNot(HaveKey(lastsAppliedConfigurationAnnotation))
+	})
+
+	It("should load plain configuration", func() {
+		obj := createCRD(true)
+		Expect(SetLastAppliedConfiguration(obj, lastsAppliedConfigurationAnnotation)).To(Succeed())
+
+		config, err := NewAnnotationStore(lastsAppliedConfigurationAnnotation).Load(context.TODO(), obj)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(config)).To(Equal(obj.Annotations[lastsAppliedConfigurationAnnotation]))
+	})
+})
+
+var _ = Describe("MergeObjectAdditive", func() {
+	It("should only add missing fields", func() {
+		desired := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"},
+			Data:       map[string]string{"operator": "value", "shared": "operator"},
+		}
+		current := desired.DeepCopy()
+		current.ResourceVersion = "5"
+		current.Data = map[string]string{"shared": "user", "user": "value"}
+
+		merged, err := MergeObjectAdditive(desired, current)
+		Expect(err).ToNot(HaveOccurred())
+
+		cm := merged.(*corev1.ConfigMap)
+		Expect(cm.ResourceVersion).To(Equal("5"))
+		Expect(cm.Data).To(Equal(map[string]string{"operator": "value", "shared": "user", "user": "value"}))
+	})
+})
+
+var _ = Describe("ReplaceObject", func() {
+	It("should replace everything but metadata", func() {
+		desired := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "ns"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &[]int32{1}[0]},
+		}
+		current := desired.DeepCopy()
+		current.ResourceVersion = "5"
+		current.Labels = map[string]string{"user": "label"}
+		current.Spec.Replicas = &[]int32{3}[0]
+		current.Spec.Paused = true
+
+		replaced, err := ReplaceObject(desired, current)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := replaced.(*appsv1.Deployment)
+		Expect(deployment.ResourceVersion).To(Equal("5"))
+		Expect(deployment.Labels).To(HaveKeyWithValue("user", "label"))
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(1))
+		Expect(deployment.Spec.Paused).To(BeFalse())
+	})
+})
+
 var _ = Describe("StripStatusFromObject", func() {
 	It("Should not alter object without status", func() {
 		in := &corev1.Secret{}
@@ -0,0 +1,83 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdkapi "kubevirt.io/controller-lifecycle-operator-sdk/api"
+)
+
+// MetaConfigStatus defines the observed state of MetaConfig
+type MetaConfigStatus struct {
+	sdkapi.MetaStatus `json:",inline"`
+}
+
+// MetaConfig is the Schema for the config API with metav1.Condition conditions
+type MetaConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConfigSpec       `json:"spec,omitempty"`
+	Status MetaConfigStatus `json:"status,omitempty"`
+}
+
+// MetaConfigList contains a list of MetaConfig
+type MetaConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetaConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MetaConfig{}, &MetaConfigList{})
+}
+
+// DeepCopyObject is copying the receiver, creating a new runtime.Object.
+func (in *MetaConfig) DeepCopyObject() runtime.Object {
+	out := new(MetaConfig)
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.MetaStatus.DeepCopyInto(&out.Status.MetaStatus)
+	return out
+}
+
+// DeepCopyObject is copying the receiver, creating a new runtime.Object.
+func (in *MetaConfigList) DeepCopyObject() runtime.Object {
+	out := new(MetaConfigList)
+	*out = *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]MetaConfig, len(in.Items))
+		for i := range in.Items {
+			out.Items[i] = *in.Items[i].DeepCopyObject().(*MetaConfig)
+		}
+	}
+	return out
+}
+
+// MetaConfigCrManager provides test CR management functionality for MetaConfig
+type MetaConfigCrManager struct {
+	ConfigCrManager
+}
+
+// IsCreating checks whether creation of the managed resources will be executed
+func (m *MetaConfigCrManager) IsCreating(cr client.Object) (bool, error) {
+	return len(cr.(*MetaConfig).Status.Conditions) == 0, nil
+}
+
+// Create creates empty CR
+func (m *MetaConfigCrManager) Create() client.Object {
+	return new(MetaConfig)
+}
+
+// Status isn't used, the status of MetaConfig is a MetaStatus
+func (m *MetaConfigCrManager) Status(client.Object) *sdkapi.Status {
+	return nil
+}
+
+// MetaStatus extracts status from the cr
+func (m *MetaConfigCrManager) MetaStatus(cr client.Object) *sdkapi.MetaStatus {
+	return &cr.(*MetaConfig).Status.MetaStatus
+}